@@ -5,20 +5,18 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io/fs"
 	"net/url"
 	"os"
 	"strings"
-	"sync"
 	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/google/uuid"
-	"github.com/icinga/icinga-go-library/backoff"
 	"github.com/icinga/icinga-go-library/config"
 	"github.com/icinga/icinga-go-library/database"
 	"github.com/icinga/icinga-go-library/logging"
 	"github.com/icinga/icinga-go-library/periodic"
-	"github.com/icinga/icinga-go-library/retry"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/internal"
 	cachev1 "github.com/icinga/icinga-kubernetes/internal/cache/v1"
@@ -26,11 +24,17 @@ import (
 	kcom "github.com/icinga/icinga-kubernetes/pkg/com"
 	"github.com/icinga/icinga-kubernetes/pkg/daemon"
 	kdatabase "github.com/icinga/icinga-kubernetes/pkg/database"
+	"github.com/icinga/icinga-kubernetes/pkg/downtime"
+	"github.com/icinga/icinga-kubernetes/pkg/leaderelection"
 	"github.com/icinga/icinga-kubernetes/pkg/metrics"
 	"github.com/icinga/icinga-kubernetes/pkg/notifications"
+	"github.com/icinga/icinga-kubernetes/pkg/resources"
 	schemav1 "github.com/icinga/icinga-kubernetes/pkg/schema/v1"
+	"github.com/icinga/icinga-kubernetes/pkg/scope"
 	syncv1 "github.com/icinga/icinga-kubernetes/pkg/sync/v1"
+	"github.com/icinga/icinga-kubernetes/pkg/telemetry"
 	k8sMysql "github.com/icinga/icinga-kubernetes/schema/mysql"
+	k8sPgsql "github.com/icinga/icinga-kubernetes/schema/pgsql"
 	"github.com/jmoiron/sqlx"
 	"github.com/okzk/sdnotify"
 	"github.com/pkg/errors"
@@ -38,10 +42,12 @@ import (
 	promv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/spf13/pflag"
 	"golang.org/x/sync/errgroup"
+	kcorev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/runtime"
-	"k8s.io/client-go/informers"
+	"k8s.io/client-go/dynamic"
 	v2 "k8s.io/client-go/informers/core/v1"
 	"k8s.io/client-go/kubernetes"
 	kcache "k8s.io/client-go/tools/cache"
@@ -49,7 +55,11 @@ import (
 	"k8s.io/klog/v2"
 )
 
-const expectedSchemaVersion = "0.4.0"
+const expectedSchemaVersion = "0.5.0"
+
+// baselineSchemaVersion is the oldest schema version from which upgrade scripts are available.
+// Older schemas can only be dropped and re-imported, see the --allow-schema-drop flag.
+const baselineSchemaVersion = "0.4.0"
 
 func main() {
 	runtime.ReallyCrash = true
@@ -57,6 +67,7 @@ func main() {
 	var glue daemon.ConfigFlagGlue
 	var showVersion bool
 	var clusterName string
+	var allowSchemaDrop bool
 
 	klog.InitFlags(nil)
 	pflag.CommandLine.AddGoFlagSet(flag.CommandLine)
@@ -69,6 +80,12 @@ func main() {
 		fmt.Sprintf("path to the config file (default: %s)", daemon.DefaultConfigPath),
 	)
 	pflag.StringVar(&clusterName, "cluster-name", "", "name of the current cluster")
+	pflag.BoolVar(
+		&allowSchemaDrop,
+		"allow-schema-drop",
+		false,
+		"drop and re-import the database schema if it cannot be upgraded to the expected version (all data is lost)",
+	)
 
 	loadingRules := kclientcmd.NewDefaultClientConfigLoadingRules()
 	loadingRules.DefaultClientConfig = &kclientcmd.DefaultClientConfig
@@ -110,7 +127,6 @@ func main() {
 
 	klog.Infof("Conntected to %s", kconfig.Host)
 
-	factory := informers.NewSharedInformerFactory(clientset, 0)
 	log := klog.NewKlogr()
 
 	var cfg daemon.Config
@@ -122,6 +138,13 @@ func main() {
 		klog.Fatal(errors.Wrap(err, "can't create configuration"))
 	}
 
+	dynamicClient, err := dynamic.NewForConfig(kconfig)
+	if err != nil {
+		klog.Fatal(err)
+	}
+
+	scoped := scope.NewScope(clientset, dynamicClient, &cfg.Scope)
+
 	logs, err := logging.NewLoggingFromConfig("Icinga Kubernetes", cfg.Logging)
 	if err != nil {
 		klog.Fatal(errors.Wrap(err, "cannot configure logging"))
@@ -138,76 +161,65 @@ func main() {
 		klog.Fatal(err)
 	}
 
+	g, ctx := errgroup.WithContext(context.Background())
+
+	if cfg.Telemetry.Listen != "" {
+		g.Go(func() error {
+			return telemetry.Serve(ctx, &cfg.Telemetry, log.WithName("telemetry"))
+		})
+	}
+
 	// When started by systemd, NOTIFY_SOCKET is set by systemd for Type=notify supervised services, which was the
 	// default setting for the Icinga for Kubernetes service. Before switching to Type=simple. For Type=notify,
 	// we need to tell systemd, that Icinga for Kubernetes finished starting up.
 	_ = sdnotify.Ready()
 
+	connected := telemetry.Health.Pending("database")
 	if !kdb.Connect() {
 		return
 	}
+	connected()
+
+	var schema string
+	var upgrades fs.FS
+	switch kdb.DriverName() {
+	case kdatabase.PostgreSQL:
+		schema, upgrades = k8sPgsql.Schema, k8sPgsql.Upgrades
+	default:
+		schema, upgrades = k8sMysql.Schema, k8sMysql.Upgrades
+	}
 
-	hasSchema, err := dbHasSchema(kdb, cfg.Database.Database)
+	// Schema handling takes place before leader election, as standby replicas already write to the database.
+	// Concurrently starting replicas are serialized by an advisory lock instead.
+	unlockSchema, err := kdb.LockSchema(ctx, cfg.Database.Database)
 	if err != nil {
 		klog.Fatal(err)
 	}
 
-	g, ctx := errgroup.WithContext(context.Background())
+	hasSchema, err := kdb.HasSchema(ctx, cfg.Database.Database)
+	if err != nil {
+		klog.Fatal(err)
+	}
 
 	if hasSchema {
-		var version string
-
-		err = retry.WithBackoff(
-			ctx,
-			func(ctx context.Context) (err error) {
-				query := "SELECT version FROM kubernetes_schema ORDER BY id DESC LIMIT 1"
-				err = kdb.QueryRowxContext(ctx, query).Scan(&version)
-				if err != nil {
-					err = kdatabase.CantPerformQuery(err, query)
-				}
-				return
-			},
-			retry.Retryable,
-			backoff.NewExponentialWithJitter(128*time.Millisecond, 1*time.Minute),
-			retry.Settings{})
+		version, err := kdb.SchemaVersion(ctx)
 		if err != nil {
 			klog.Fatal(err)
 		}
 
-		if version != expectedSchemaVersion {
-			err = retry.WithBackoff(
-				ctx,
-				func(ctx context.Context) (err error) {
-					rows, err := kdb.Query(
-						kdb.Rebind("SELECT table_name FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA=?"),
-						cfg.Database.Database,
-					)
-					if err != nil {
-						klog.Fatal(err)
-					}
-					defer func() {
-						_ = rows.Close()
-					}()
+		migrations, err := kdatabase.LoadMigrations(upgrades, "upgrades", baselineSchemaVersion)
+		if err != nil {
+			klog.Fatal(err)
+		}
 
-					dbLog.Info("Dropping schema")
+		if err := kdb.MigrateSchema(ctx, migrations, version, expectedSchemaVersion); err != nil {
+			if !errors.Is(err, kdatabase.ErrNoUpgradePath) || !allowSchemaDrop {
+				klog.Fatal(err)
+			}
 
-					for rows.Next() {
-						var tableName string
-						if err := rows.Scan(&tableName); err != nil {
-							klog.Fatal(err)
-						}
+			dbLog.Info("Dropping schema", "reason", err.Error())
 
-						_, err := kdb.Exec(fmt.Sprintf(`DROP TABLE %s`, tableName))
-						if err != nil {
-							klog.Fatal(err)
-						}
-					}
-					return
-				},
-				retry.Retryable,
-				backoff.NewExponentialWithJitter(128*time.Millisecond, 1*time.Minute),
-				retry.Settings{})
-			if err != nil {
+			if err := kdb.DropSchema(ctx, cfg.Database.Database); err != nil {
 				klog.Fatal(err)
 			}
 
@@ -218,15 +230,13 @@ func main() {
 	if !hasSchema {
 		dbLog.Info("Importing schema")
 
-		for _, ddl := range strings.Split(k8sMysql.Schema, ";") {
-			if ddl = strings.TrimSpace(ddl); ddl != "" {
-				if _, err := kdb.Exec(ddl); err != nil {
-					klog.Fatal(err)
-				}
-			}
+		if err := kdb.ImportSchema(ctx, schema); err != nil {
+			klog.Fatal(err)
 		}
 	}
 
+	unlockSchema()
+
 	if clusterName == "" {
 		clusterName = os.Getenv("ICINGA_FOR_KUBERNETES_CLUSTER_NAME")
 	}
@@ -249,13 +259,25 @@ func main() {
 		klog.Error(errors.Wrap(err, "cannot update cluster"))
 	}
 
-	if _, err := kdb.ExecContext(ctx, "DELETE FROM kubernetes_instance WHERE cluster_uuid = ?", clusterInstance.Uuid); err != nil {
-		klog.Fatal(errors.Wrap(err, "cannot delete instance"))
+	instanceId := uuid.New()
+
+	var elector *leaderelection.Elector
+	if cfg.LeaderElection.Enabled {
+		hostname, err := os.Hostname()
+		if err != nil {
+			klog.Fatal(errors.Wrap(err, "cannot get hostname"))
+		}
+
+		elector, err = leaderelection.NewElector(
+			clientset, &cfg.LeaderElection, hostname+"_"+instanceId.String(), log.WithName("leader-election"))
+		if err != nil {
+			klog.Fatal(err)
+		}
 	}
+
 	// ,omitempty
 	var kubernetesVersion string
 	var kubernetesHeartbeat time.Time
-	instanceId := uuid.New()
 	defer periodic.Start(ctx, 55*time.Second, func(tick periodic.Tick) {
 		version, err := clientset.Discovery().ServerVersion()
 		if err == nil {
@@ -273,154 +295,373 @@ func main() {
 				Bool:  err == nil,
 				Valid: true,
 			},
+			Role:      schemav1.InstanceRoleLeader,
 			Message:   schemav1.NewNullableString(err),
 			Heartbeat: types.UnixMilli(tick.Time),
 		}
 
+		if elector != nil && !elector.IsLeader() {
+			instance.Role = schemav1.InstanceRoleStandby
+		}
+
 		stmt, _ := kdb.BuildUpsertStmt(instance)
 
 		if _, err := kdb.NamedExecContext(ctx, stmt, instance); err != nil {
 			klog.Error(errors.Wrap(err, "cannot update instance"))
 		}
+
+		// Instances of this cluster that have not sent a heartbeat for a while are gone,
+		// e.g. previous runs of this instance or replicas that have been scaled down.
+		if _, err := kdb.ExecContext(
+			ctx,
+			kdb.Rebind("DELETE FROM kubernetes_instance WHERE cluster_uuid = ? AND heartbeat < ?"),
+			clusterInstance.Uuid,
+			types.UnixMilli(tick.Time.Add(-5*time.Minute)),
+		); err != nil {
+			klog.Error(errors.Wrap(err, "cannot delete stale instances"))
+		}
+
+		telemetry.Health.Heartbeat(tick.Time, instance.KubernetesApiReachable.Bool)
 	}, periodic.Immediate()).Stop()
 
+	if elector != nil {
+		go elector.Run(ctx)
+
+		// Standby instances keep their database connection and heartbeat and stay warm by listing and watching
+		// all resources in scope, so that a failover does not have to wait for the caches of the informers.
+		// Apart from that, they do not write anything until they become the leader.
+		cachesSynced := telemetry.Health.Pending("standby cache sync")
+
+		for _, kind := range resources.Kinds {
+			if cfg.Resources.Options(kind).Enabled {
+				for _, namespace := range scoped.NamespacesOf(kind.Name) {
+					kind.Informer(scoped.Factory(kind.Name, namespace))
+				}
+			}
+		}
+
+		for _, cr := range cfg.CustomResources {
+			gvr := cr.GroupVersionResource()
+
+			// Errors are logged below when the custom resource is synchronized.
+			namespaced, err := isNamespaced(clientset, gvr)
+			if err != nil {
+				continue
+			}
+
+			namespaces := []string{v1.NamespaceAll}
+			if namespaced {
+				namespaces = scoped.Namespaces()
+			}
+
+			for _, namespace := range namespaces {
+				scoped.DynamicFactory(gvr, namespaced, namespace).ForResource(gvr).Informer()
+			}
+		}
+
+		scoped.Start(ctx)
+
+		klog.Info("Waiting for caches to sync")
+
+		if err := scoped.WaitForCacheSync(ctx); err != nil {
+			klog.Fatal(err)
+		}
+
+		cachesSynced()
+
+		klog.Info("Waiting for leadership")
+
+		select {
+		case <-elector.Leading():
+		case <-ctx.Done():
+			klog.Fatal(ctx.Err())
+		}
+
+		go func() {
+			<-elector.Stopped()
+
+			// Exit immediately so that nothing is written once another instance may have taken over.
+			if ctx.Err() == nil {
+				klog.Fatal("Leadership lost")
+			}
+		}()
+	}
+
+	// Hold readiness until all syncs below have been launched.
+	// Each sync then holds it further until the cache of its informer has been synced.
+	started := telemetry.Health.Pending("startup")
+
 	if err := internal.SyncNotificationsConfig(ctx, db, &cfg.Notifications, clusterInstance.Uuid); err != nil {
 		klog.Fatal(err)
 	}
 
-	if cfg.Notifications.Url != "" {
-		klog.Infof("Sending notifications to %s", cfg.Notifications.Url)
+	if err := downtime.Sync(ctx, db, cfg.Downtimes, clusterInstance.Uuid); err != nil {
+		klog.Fatal(err)
+	}
+
+	// customResourceEvents receives the upserted and deleted custom resources that are forwarded to notifications.
+	customResourceEvents := make(map[kschema.GroupResource]customResourceEventsChannels)
+
+	if cfg.Notifications.Enabled() {
+		if cfg.Notifications.Url != "" {
+			klog.Infof("Sending notifications to %s", cfg.Notifications.Url)
+		}
+		for _, sink := range cfg.Notifications.Sinks {
+			klog.Infof("Sending notifications to %s sink %s", sink.Type, sink.Name)
+		}
 
 		nclient, err := notifications.NewClient("icinga-kubernetes/"+internal.Version.Version, cfg.Notifications, db)
 		if err != nil {
 			klog.Fatal(err)
 		}
 
-		type objectTags struct {
-			UUID        string `json:"uuid"`
-			ClusterUUID string `json:"cluster_uuid"`
-			Resource    string `json:"resource"`
-			Name        string `json:"name"`
-			Namespace   string `json:"namespace"`
+		if err := nclient.RestoreStates(ctx, clusterInstance.Uuid); err != nil {
+			klog.Fatal(err)
 		}
 
-		type incident struct {
-			// Incident   string `json:"incident"`
-			ObjectTags objectTags `json:"object_tags"`
-			// Severity string `json:"severity"`
+		if err := nclient.RestoreOutbox(ctx, clusterInstance.Uuid); err != nil {
+			klog.Fatal(err)
 		}
 
-		defer periodic.Start(ctx, time.Hour, func(tick periodic.Tick) {
-			r, err := nclient.Incidents(ctx)
-			if err != nil {
-				klog.Errorf("Cannot fetch incidents: %v", err)
-				return
+		g.Go(func() error {
+			return nclient.DrainOutbox(ctx, clusterInstance.Uuid)
+		})
+
+		downtimes := downtime.NewDowntimes(db, clusterInstance.Uuid)
+		if err := downtimes.Load(ctx); err != nil {
+			klog.Fatal(err)
+		}
+		nclient.SetDowntimes(downtimes)
+
+		// Reload downtimes periodically, as they can also be created via Icinga for Kubernetes Web.
+		defer periodic.Start(ctx, time.Minute, func(periodic.Tick) {
+			if err := downtimes.Load(ctx); err != nil {
+				klog.Errorf("Cannot reload downtimes: %v", err)
+			}
+		}).Stop()
+
+		if cfg.Resources.Enabled("namespaces") {
+			// Annotations of namespaces act as defaults for the notifications of the resources in them.
+			// The informers are shared with the synchronization of namespaces.
+			var namespaceInformers []v2.NamespaceInformer
+			for _, namespace := range scoped.NamespacesOf("namespaces") {
+				namespaceInformers = append(namespaceInformers, scoped.Factory("namespaces", namespace).Core().V1().Namespaces())
 			}
-			defer func() { _ = r.Close() }()
 
-			var incidents []incident
-			if err := json.NewDecoder(r).Decode(&incidents); err != nil {
-				klog.Errorf("Cannot decode incidents: %v", err)
-				return
+			nclient.SetNamespaceAnnotations(func(name string) map[string]string {
+				for _, informer := range namespaceInformers {
+					if namespace, err := informer.Lister().Get(name); err == nil {
+						return namespace.Annotations
+					}
+				}
+
+				return nil
+			})
+		}
+
+		if cfg.Notifications.ResendInterval > 0 {
+			defer periodic.Start(ctx, cfg.Notifications.ResendInterval, func(periodic.Tick) {
+				nclient.Resend(ctx)
+			}).Stop()
+		}
+
+		// States of resources that no longer exist are pruned periodically for all sinks,
+		// e.g. of resources deleted while not running.
+		defer periodic.Start(ctx, time.Hour, func(periodic.Tick) {
+			if err := nclient.PruneStates(ctx, clusterInstance.Uuid); err != nil {
+				klog.Errorf("Cannot prune notification states: %v", err)
 			}
+		}, periodic.Immediate()).Stop()
 
-			objectTagMap := make(map[string]objectTags)
-			uuidMap := make(map[string][]types.UUID)
-			for _, inc := range incidents {
-				objectTagMap[inc.ObjectTags.UUID] = inc.ObjectTags
-				uuidMap[inc.ObjectTags.Resource] = append(uuidMap[inc.ObjectTags.Resource], types.UUID{UUID: uuid.MustParse(inc.ObjectTags.UUID)})
+		// Incidents only exist in Icinga Notifications.
+		if cfg.Notifications.Url != "" {
+			type objectTags struct {
+				UUID        string `json:"uuid"`
+				ClusterUUID string `json:"cluster_uuid"`
+				Resource    string `json:"resource"`
+				Name        string `json:"name"`
+				Namespace   string `json:"namespace"`
 			}
 
-			ng, nctx := errgroup.WithContext(ctx)
+			type incident struct {
+				// Incident   string `json:"incident"`
+				ObjectTags objectTags `json:"object_tags"`
+				// Severity string `json:"severity"`
+			}
 
-			for kind, uuids := range uuidMap {
-				ng.Go(func() error {
-					q, args, err := sqlx.In(fmt.Sprintf("SELECT uuid FROM %s WHERE uuid IN (?)", kind), uuids)
-					if err != nil {
-						return err
-					}
+			// Incidents of deleted resources are resolved immediately, see Client.StreamDeletes.
+			// As a safety net, incidents whose resources no longer exist are resolved periodically,
+			// e.g. of resources deleted while not running.
+			defer periodic.Start(ctx, time.Hour, func(tick periodic.Tick) {
+				r, err := nclient.Incidents(ctx)
+				if err != nil {
+					klog.Errorf("Cannot fetch incidents: %v", err)
+					return
+				}
+				defer func() { _ = r.Close() }()
 
-					rows, err := db.QueryxContext(nctx, q, args...)
-					if err != nil {
-						return err
+				var incidents []incident
+				if err := json.NewDecoder(r).Decode(&incidents); err != nil {
+					klog.Errorf("Cannot decode incidents: %v", err)
+					return
+				}
+
+				objectTagMap := make(map[string]objectTags)
+				uuidMap := make(map[string][]types.UUID)
+				for _, inc := range incidents {
+					// Leave incidents of resources out of scope alone, as they may be managed by another instance.
+					namespace := inc.ObjectTags.Namespace
+					if inc.ObjectTags.Resource == "namespace" {
+						namespace = inc.ObjectTags.Name
+					}
+					if namespace != "" && !scoped.InNamespace(namespace) {
+						continue
 					}
-					defer func() { _ = rows.Close() }()
-					for rows.Next() {
-						var _uuid types.UUID
-						if err := rows.Scan(&_uuid); err != nil {
+
+					objectTagMap[inc.ObjectTags.UUID] = inc.ObjectTags
+					uuidMap[inc.ObjectTags.Resource] = append(uuidMap[inc.ObjectTags.Resource], types.UUID{UUID: uuid.MustParse(inc.ObjectTags.UUID)})
+				}
+
+				ng, nctx := errgroup.WithContext(ctx)
+
+				for kind, uuids := range uuidMap {
+					ng.Go(func() error {
+						q, args, err := sqlx.In(fmt.Sprintf("SELECT uuid FROM %s WHERE uuid IN (?)", kind), uuids)
+						if err != nil {
 							return err
 						}
 
-						delete(objectTagMap, _uuid.String())
+						rows, err := db.QueryxContext(nctx, db.Rebind(q), args...)
+						if err != nil {
+							return err
+						}
+						defer func() { _ = rows.Close() }()
+						for rows.Next() {
+							var _uuid types.UUID
+							if err := rows.Scan(&_uuid); err != nil {
+								return err
+							}
+
+							delete(objectTagMap, _uuid.String())
+						}
+
+						return nil
+					})
+				}
+
+				if err := ng.Wait(); err != nil {
+					klog.Errorf("Cannot fetch orphaned incidents: %v", err)
+				}
+
+				for _, tags := range objectTagMap {
+					var clusterUuid types.UUID
+					_tags := map[string]string{
+						"uuid":      tags.UUID,
+						"resource":  tags.Resource,
+						"name":      tags.Name,
+						"namespace": tags.Namespace,
+					}
+					if tags.ClusterUUID != "" {
+						clusterUuid = types.UUID{UUID: uuid.MustParse(tags.ClusterUUID)}
+						_tags["cluster_uuid"] = tags.ClusterUUID
+					}
+					ev := notifications.Event{
+						Uuid:        types.UUID{UUID: uuid.MustParse(tags.UUID)},
+						ClusterUuid: clusterUuid,
+						Kind:        tags.Resource,
+						Name:        kcache.NewObjectName(tags.Namespace, tags.Name).String(),
+						Severity:    "ok",
+						Message:     "Automatically resolving the incident because of an orphaned resource.",
+						URL:         &url.URL{Path: fmt.Sprintf("/%s", strings.ReplaceAll(tags.Resource, "_", "")), RawQuery: fmt.Sprintf("id=%s", tags.UUID)},
+						Tags:        _tags,
+						ExtraTags:   nil,
 					}
+					klog.Infof("Deleting orphaned incident: %q", ev.Name)
+					if err := nclient.ProcessEvent(ctx, ev); err != nil {
+						klog.Errorf("Cannot delete orphaned incident: %v", err)
 
-					return nil
+						continue
+					}
+
+					nclient.Forget(ctx, ev.Uuid)
+				}
+			}, periodic.Immediate()).Stop()
+		}
+
+		for _, kind := range resources.Kinds {
+			if options := cfg.Resources.Options(kind); options.Enabled && options.Notifications {
+				upserts := kind.Multiplexer.UpsertEvents().Out()
+				deletes := kind.Multiplexer.DeleteEvents().Out()
+				g.Go(func() error {
+					return nclient.Stream(ctx, upserts)
+				})
+				g.Go(func() error {
+					return nclient.StreamDeletes(ctx, deletes)
 				})
 			}
+		}
 
-			if err := ng.Wait(); err != nil {
-				klog.Errorf("Cannot fetch orphaned incidents: %v", err)
-			}
+		if cfg.Notifications.Containers && cfg.Resources.Enabled("pods") {
+			upsertPods := cachev1.Multiplexers().Pods().UpsertEvents().Out()
+			deletePods := cachev1.Multiplexers().Pods().DeleteEvents().Out()
+			upserts := make(chan any)
+			deletes := make(chan any)
+			g.Go(func() error {
+				return schemav1.StreamContainers(ctx, upsertPods, deletePods, upserts, deletes)
+			})
+			g.Go(func() error {
+				return nclient.Stream(ctx, upserts)
+			})
+			g.Go(func() error {
+				return nclient.StreamDeletes(ctx, deletes)
+			})
+		}
 
-			for _, tags := range objectTagMap {
-				var clusterUuid types.UUID
-				_tags := map[string]string{
-					"uuid":      tags.UUID,
-					"resource":  tags.Resource,
-					"name":      tags.Name,
-					"namespace": tags.Namespace,
-				}
-				if tags.ClusterUUID != "" {
-					clusterUuid = types.UUID{UUID: uuid.MustParse(tags.ClusterUUID)}
-					_tags["cluster_uuid"] = tags.ClusterUUID
-				}
-				ev := notifications.Event{
-					Uuid:        types.UUID{UUID: uuid.MustParse(tags.UUID)},
-					ClusterUuid: clusterUuid,
-					Kind:        tags.Resource,
-					Name:        kcache.NewObjectName(tags.Namespace, tags.Name).String(),
-					Severity:    "ok",
-					Message:     "Automatically resolving the incident because of an orphaned resource.",
-					URL:         &url.URL{Path: fmt.Sprintf("/%s", strings.ReplaceAll(tags.Resource, "_", "")), RawQuery: fmt.Sprintf("id=%s", tags.UUID)},
-					Tags:        _tags,
-					ExtraTags:   nil,
-				}
-				klog.Infof("Deleting orphaned incident: %q", ev.Name)
-				if err := nclient.ProcessEvent(ctx, ev); err != nil {
-					klog.Errorf("Cannot delete orphaned incident: %v", err)
-				}
+		for _, cr := range cfg.CustomResources {
+			if cr.Notifications {
+				events := customResourceEventsChannels{upserts: make(chan any), deletes: make(chan any)}
+				customResourceEvents[cr.GroupVersionResource().GroupResource()] = events
+				g.Go(func() error {
+					return nclient.Stream(ctx, events.upserts)
+				})
+				g.Go(func() error {
+					return nclient.StreamDeletes(ctx, events.deletes)
+				})
 			}
-		}, periodic.Immediate()).Stop()
-
-		g.Go(func() error {
-			return nclient.Stream(ctx, cachev1.Multiplexers().Nodes().UpsertEvents().Out())
-		})
+		}
+	}
 
-		g.Go(func() error {
-			return nclient.Stream(ctx, cachev1.Multiplexers().DaemonSets().UpsertEvents().Out())
-		})
+	var podInformers []v2.PodInformer
+	if cfg.Resources.Enabled("pods") {
+		for _, namespace := range scoped.NamespacesOf("pods") {
+			podInformers = append(podInformers, scoped.Factory("pods", namespace).Core().V1().Pods())
+		}
 
+		upsertPods := cachev1.Multiplexers().Pods().UpsertEvents().Out()
+		deletePods := cachev1.Multiplexers().Pods().DeleteEvents().Out()
 		g.Go(func() error {
-			return nclient.Stream(ctx, cachev1.Multiplexers().StatefulSets().UpsertEvents().Out())
-		})
+			schemav1.SyncContainers(ctx, kdb, g, upsertPods, deletePods)
 
-		g.Go(func() error {
-			return nclient.Stream(ctx, cachev1.Multiplexers().Deployments().UpsertEvents().Out())
+			return nil
 		})
+	}
 
-		g.Go(func() error {
-			return nclient.Stream(ctx, cachev1.Multiplexers().ReplicaSets().UpsertEvents().Out())
-		})
+	if cfg.Resources.Enabled("pods") && cfg.Resources.Enabled("services") {
+		var serviceInformers []v2.ServiceInformer
+		for _, namespace := range scoped.NamespacesOf("services") {
+			serviceInformers = append(serviceInformers, scoped.Factory("services", namespace).Core().V1().Services())
+		}
 
+		upsertPods := cachev1.Multiplexers().Pods().UpsertEvents().Out()
+		deletePods := cachev1.Multiplexers().Pods().DeleteEvents().Out()
+		upsertServices := cachev1.Multiplexers().Services().UpsertEvents().Out()
 		g.Go(func() error {
-			return nclient.Stream(ctx, cachev1.Multiplexers().Pods().UpsertEvents().Out())
+			return SyncServicePods(
+				ctx, kdb, serviceInformers, podInformers, upsertPods, deletePods, upsertServices,
+				cfg.Sync.ReconcileInterval)
 		})
 	}
 
-	g.Go(func() error {
-		return SyncServicePods(ctx, kdb, factory.Core().V1().Services(), factory.Core().V1().Pods())
-	})
-
 	err = internal.SyncPrometheusConfig(ctx, db, &cfg.Prometheus, clusterInstance.Uuid)
 	if err != nil {
 		klog.Error(errors.Wrap(err, "cannot sync prometheus config"))
@@ -433,6 +674,7 @@ func main() {
 		}
 	}
 
+	var metricSource metrics.Source
 	if cfg.Prometheus.Url != "" {
 		basicAuthTransport := &kcom.BasicAuthTransport{}
 
@@ -454,273 +696,305 @@ func main() {
 		}
 
 		promApiClient := promv1.NewAPI(promClient)
-		promMetricSync := metrics.NewPromMetricSync(promApiClient, db, logs.GetChildLogger("prometheus"))
-
-		g.Go(func() error {
-			return promMetricSync.Nodes(ctx, factory.Core().V1().Nodes().Informer())
-		})
+		metricSource = metrics.NewPromMetricSync(
+			promApiClient, db, logs.GetChildLogger("prometheus"), metrics.PromQueries(cfg.Prometheus.Queries))
+	} else if metrics.MetricsServerAvailable(clientset) {
+		klog.Info("No Prometheus available, synchronizing CPU and memory usage from the metrics API instead")
 
-		g.Go(func() error {
-			return promMetricSync.Pods(ctx, factory.Core().V1().Pods().Informer())
-		})
+		metricSource = metrics.NewMetricsServerSync(clientset, db, logs.GetChildLogger("metrics-server"))
 	}
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Core().V1().Namespaces().Informer(), log.WithName("namespaces"), schemav1.NewNamespace)
-
-		return s.Run(ctx)
-	})
-
-	wg := sync.WaitGroup{}
-
-	wg.Add(1)
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Core().V1().Nodes().Informer(), log.WithName("nodes"), schemav1.NewNode)
-
-		var forwardForNotifications []syncv1.Feature
-		if cfg.Notifications.Url != "" {
-			forwardForNotifications = append(
-				forwardForNotifications,
-				syncv1.WithOnUpsert(database.OnSuccessSendTo(cachev1.Multiplexers().Nodes().UpsertEvents().In())),
-				syncv1.WithOnDelete(database.OnSuccessSendTo(cachev1.Multiplexers().Nodes().DeleteEvents().In())),
-			)
+	if metricSource != nil {
+		if cfg.Resources.Enabled("nodes") {
+			g.Go(func() error {
+				return metricSource.Nodes(ctx, scoped.Factory("nodes", v1.NamespaceAll).Core().V1().Nodes().Informer())
+			})
 		}
 
-		wg.Done()
-
-		return s.Run(ctx, forwardForNotifications...)
-	})
-
-	wg.Add(1)
-	g.Go(func() error {
-		schemav1.SyncContainers(
-			ctx,
-			kdb,
-			g,
-			cachev1.Multiplexers().Pods().UpsertEvents().Out(),
-			cachev1.Multiplexers().Pods().DeleteEvents().Out(),
-		)
+		if cfg.Resources.Enabled("pods") {
+			var informers []kcache.SharedIndexInformer
+			for _, pods := range podInformers {
+				informers = append(informers, pods.Informer())
+			}
 
-		f := schemav1.NewPodFactory(clientset)
-		s := syncv1.NewSync(kdb, factory.Core().V1().Pods().Informer(), log.WithName("pods"), f.New)
+			g.Go(func() error {
+				return metricSource.Pods(ctx, informers...)
+			})
+		}
 
-		wg.Done()
+		// Only Prometheus provides metrics of the cluster and containers.
+		if promMetricSync, ok := metricSource.(*metrics.PromMetricSync); ok {
+			g.Go(func() error {
+				return promMetricSync.Clusters(ctx)
+			})
 
-		return s.Run(
-			ctx,
-			syncv1.WithOnUpsert(database.OnSuccessSendTo(cachev1.Multiplexers().Pods().UpsertEvents().In())),
-			syncv1.WithOnDelete(database.OnSuccessSendTo(cachev1.Multiplexers().Pods().DeleteEvents().In())),
-		)
-	})
-
-	wg.Add(1)
-	g.Go(func() error {
-		s := syncv1.NewSync(
-			kdb, factory.Apps().V1().Deployments().Informer(), log.WithName("deployments"), schemav1.NewDeployment)
+			if cfg.Resources.Enabled("pods") {
+				var informers []kcache.SharedIndexInformer
+				for _, pods := range podInformers {
+					informers = append(informers, pods.Informer())
+				}
 
-		var forwardForNotifications []syncv1.Feature
-		if cfg.Notifications.Url != "" {
-			forwardForNotifications = append(
-				forwardForNotifications,
-				syncv1.WithOnUpsert(database.OnSuccessSendTo(cachev1.Multiplexers().Deployments().UpsertEvents().In())),
-				syncv1.WithOnDelete(database.OnSuccessSendTo(cachev1.Multiplexers().Deployments().DeleteEvents().In())),
-			)
+				g.Go(func() error {
+					return promMetricSync.Containers(ctx, informers...)
+				})
+			}
 		}
+	}
 
-		wg.Done()
-
-		return s.Run(ctx, forwardForNotifications...)
-	})
+	// requeuers allow requeuing resources whose Icinga state depends on the storage usage collected from the kubelets.
+	requeuers := make(map[string]*syncv1.Requeuer)
 
-	wg.Add(1)
-	g.Go(func() error {
-		s := syncv1.NewSync(
-			kdb, factory.Apps().V1().DaemonSets().Informer(), log.WithName("daemon-sets"), schemav1.NewDaemonSet)
+	if !cfg.Kubelet.Disabled && cfg.Resources.Enabled("nodes") {
+		schemav1.SetStorageThresholds(cfg.Kubelet.Warning/100, cfg.Kubelet.Critical/100)
 
-		var forwardForNotifications []syncv1.Feature
-		if cfg.Notifications.Url != "" {
-			forwardForNotifications = append(
-				forwardForNotifications,
-				syncv1.WithOnUpsert(database.OnSuccessSendTo(cachev1.Multiplexers().DaemonSets().UpsertEvents().In())),
-				syncv1.WithOnDelete(database.OnSuccessSendTo(cachev1.Multiplexers().DaemonSets().DeleteEvents().In())),
-			)
+		var pvcInformers, informers []kcache.SharedIndexInformer
+		if cfg.Resources.Enabled("persistentvolumeclaims") {
+			requeuers["persistentvolumeclaims"] = syncv1.NewRequeuer()
+			for _, namespace := range scoped.NamespacesOf("persistentvolumeclaims") {
+				pvcInformers = append(pvcInformers,
+					scoped.Factory("persistentvolumeclaims", namespace).Core().V1().PersistentVolumeClaims().Informer())
+			}
+		}
+		if cfg.Resources.Enabled("pods") {
+			requeuers["pods"] = syncv1.NewRequeuer()
+			for _, pods := range podInformers {
+				informers = append(informers, pods.Informer())
+			}
 		}
 
-		wg.Done()
+		kubeletSync := metrics.NewKubeletSync(
+			clientset, db, logs.GetChildLogger("kubelet"), cfg.Kubelet.Interval,
+			requeuers["persistentvolumeclaims"], requeuers["pods"])
 
-		return s.Run(ctx, forwardForNotifications...)
-	})
+		g.Go(func() error {
+			return kubeletSync.Run(
+				ctx, scoped.Factory("nodes", v1.NamespaceAll).Core().V1().Nodes().Informer(), pvcInformers, informers)
+		})
+	}
 
-	wg.Add(1)
-	g.Go(func() error {
-		s := syncv1.NewSync(
-			kdb, factory.Apps().V1().ReplicaSets().Informer(), log.WithName("replica-sets"), schemav1.NewReplicaSet)
+	// warmupInScope restricts the warmup of the given resource to rows in scope of the given namespace.
+	warmupInScope := func(resource, namespace string) syncv1.Feature {
+		return syncv1.WithWarmupFilter(scoped.WarmupFilter(resource, namespace))
+	}
 
-		var forwardForNotifications []syncv1.Feature
-		if cfg.Notifications.Url != "" {
-			forwardForNotifications = append(
-				forwardForNotifications,
-				syncv1.WithOnUpsert(database.OnSuccessSendTo(cachev1.Multiplexers().ReplicaSets().UpsertEvents().In())),
-				syncv1.WithOnDelete(database.OnSuccessSendTo(cachev1.Multiplexers().ReplicaSets().DeleteEvents().In())),
-			)
+	// syncName names the sync of the given resource in the given namespace,
+	// so that the telemetry of syncs for different namespaces does not collide.
+	syncName := func(resource, namespace string) string {
+		if namespace == v1.NamespaceAll {
+			return resource
 		}
 
-		wg.Done()
+		return resource + "/" + namespace
+	}
 
-		return s.Run(ctx, forwardForNotifications...)
-	})
+	for _, kind := range resources.Kinds {
+		options := cfg.Resources.Options(kind)
+		if !options.Enabled {
+			klog.V(2).Infof("Not synchronizing %s", kind.Name)
 
-	wg.Add(1)
-	g.Go(func() error {
-		s := syncv1.NewSync(
-			kdb, factory.Apps().V1().StatefulSets().Informer(), log.WithName("stateful-sets"), schemav1.NewStatefulSet)
-
-		var forwardForNotifications []syncv1.Feature
-		if cfg.Notifications.Url != "" {
-			forwardForNotifications = append(
-				forwardForNotifications,
-				syncv1.WithOnUpsert(database.OnSuccessSendTo(cachev1.Multiplexers().StatefulSets().UpsertEvents().In())),
-				syncv1.WithOnDelete(database.OnSuccessSendTo(cachev1.Multiplexers().StatefulSets().DeleteEvents().In())),
-			)
+			continue
 		}
 
-		wg.Done()
+		multiplexed := kind.Multiplexer != nil &&
+			(kind.Multiplexed || options.Notifications && cfg.Notifications.Enabled())
 
-		return s.Run(ctx, forwardForNotifications...)
-	})
-
-	g.Go(func() error {
-		f := schemav1.NewServiceFactory(clientset)
-		s := syncv1.NewSync(kdb, factory.Core().V1().Services().Informer(), log.WithName("services"), f.NewService)
+		for _, namespace := range scoped.NamespacesOf(kind.Name) {
+			s := syncv1.NewSync(
+				kdb, kind.Informer(scoped.Factory(kind.Name, namespace)),
+				log.WithName(kind.LogName), kind.Factory(clientset))
 
-		return s.Run(
-			ctx,
-			syncv1.WithOnUpsert(database.OnSuccessSendTo(cachev1.Multiplexers().Services().UpsertEvents().In())),
-		)
-	})
+			features := []syncv1.Feature{
+				syncv1.WithName(syncName(kind.Name, namespace)),
+				warmupInScope(kind.Name, namespace),
+				syncv1.WithReconcile(cfg.Sync.ReconcileInterval),
+			}
+			if options.NoDelete {
+				features = append(features, syncv1.WithNoDelete())
+			}
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Discovery().V1().EndpointSlices().Informer(), log.WithName("endpoints"), schemav1.NewEndpointSlice)
+			if options.NoWarmup {
+				features = append(features, syncv1.WithNoWarumup())
+			}
 
-		return s.Run(ctx)
-	})
+			if requeuer, ok := requeuers[kind.Name]; ok {
+				features = append(features, syncv1.WithRequeuer(requeuer))
+			}
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Core().V1().Secrets().Informer(), log.WithName("secrets"), schemav1.NewSecret)
-		return s.Run(ctx)
-	})
+			if multiplexed {
+				features = append(
+					features,
+					syncv1.WithOnUpsert(database.OnSuccessSendTo(kind.Multiplexer.UpsertEvents().In())),
+					syncv1.WithOnDelete(database.OnSuccessSendTo(kind.Multiplexer.DeleteEvents().In())),
+				)
+			}
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Core().V1().ConfigMaps().Informer(), log.WithName("config-maps"), schemav1.NewConfigMap)
+			g.Go(func() error {
+				return s.Run(ctx, features...)
+			})
+		}
+	}
 
-		return s.Run(ctx)
-	})
+	for _, cr := range cfg.CustomResources {
+		gvr := cr.GroupVersionResource()
+		gr := gvr.GroupResource()
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Events().V1().Events().Informer(), log.WithName("events"), schemav1.NewEvent)
+		namespaced, err := isNamespaced(clientset, gvr)
+		if err != nil {
+			klog.Errorf("Not synchronizing custom resource %s: %v", gr, err)
 
-		return s.Run(ctx, syncv1.WithNoDelete(), syncv1.WithNoWarumup())
-	})
+			continue
+		}
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Core().V1().PersistentVolumeClaims().Informer(), log.WithName("pvcs"), schemav1.NewPvc)
+		options := cr.Options()
+		namespaces := []string{v1.NamespaceAll}
+		if namespaced {
+			namespaces = scoped.Namespaces()
+		}
 
-		return s.Run(ctx)
-	})
+		for _, namespace := range namespaces {
+			s := syncv1.NewSync(
+				kdb, scoped.DynamicFactory(gvr, namespaced, namespace).ForResource(gvr).Informer(),
+				log.WithName("custom-resources").WithValues("resource", gr.String()),
+				schemav1.NewCustomResourceFactory(gvr))
+
+			features := []syncv1.Feature{
+				syncv1.WithName(syncName(gr.String(), namespace)),
+				// Rows of all custom resources share the same table.
+				syncv1.WithWarmupFilter("api_group = ? AND resource = ?", []interface{}{gvr.Group, gvr.Resource}),
+				syncv1.WithReconcile(cfg.Sync.ReconcileInterval),
+			}
+			if namespaced {
+				features = append(features, warmupInScope(gr.String(), namespace))
+			}
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Core().V1().PersistentVolumes().Informer(), log.WithName("persistent-volumes"), schemav1.NewPersistentVolume)
+			if options.NoDelete {
+				features = append(features, syncv1.WithNoDelete())
+			}
 
-		return s.Run(ctx)
-	})
+			if options.NoWarmup {
+				features = append(features, syncv1.WithNoWarumup())
+			}
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Batch().V1().Jobs().Informer(), log.WithName("jobs"), schemav1.NewJob)
+			if events, ok := customResourceEvents[gr]; ok {
+				features = append(
+					features,
+					syncv1.WithOnUpsert(database.OnSuccessSendTo[any](events.upserts)),
+					syncv1.WithOnDelete(database.OnSuccessSendTo[any](events.deletes)),
+				)
+			}
 
-		return s.Run(ctx)
-	})
+			g.Go(func() error {
+				return s.Run(ctx, features...)
+			})
+		}
+	}
 
+	// All inputs and outputs of the multiplexers have been registered above.
 	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Batch().V1().CronJobs().Informer(), log.WithName("cron-jobs"), schemav1.NewCronJob)
+		klog.V(2).Info("Starting multiplexers")
 
-		return s.Run(ctx)
+		return cachev1.Multiplexers().Run(ctx)
 	})
 
-	g.Go(func() error {
-		s := syncv1.NewSync(kdb, factory.Networking().V1().Ingresses().Informer(), log.WithName("ingresses"), schemav1.NewIngress)
+	// cleanup periodically deletes the rows older than the given retention, unless it is zero.
+	cleanup := func(stmt kdatabase.CleanupStmt, retention time.Duration) {
+		if retention == 0 {
+			klog.V(2).Infof("Not cleaning up %s", stmt.Table)
 
-		return s.Run(ctx)
-	})
+			return
+		}
 
-	g.Go(func() error {
-		wg.Wait()
+		g.Go(func() error {
+			return kdb.PeriodicCleanup(ctx, stmt, retention, cfg.Retention.Interval, cfg.Retention.BatchSize)
+		})
+	}
 
-		klog.V(2).Info("Starting multiplexers")
+	retentions := cfg.Retention.Tables()
+	rollupRetentions := map[string]time.Duration{"5m": cfg.Retention.Metric5m, "1h": cfg.Retention.Metric1h}
+	for _, table := range metrics.MetricTables {
+		for _, rollup := range metrics.Rollups {
+			retentions[rollup.Table(table)] = rollupRetentions[rollup.Suffix]
+		}
+	}
 
-		return cachev1.Multiplexers().Run(ctx)
-	})
+	cleanup(kdatabase.CleanupStmt{
+		Table:  "event",
+		PK:     "uuid",
+		Column: "created",
+	}, retentions["event"])
 
-	g.Go(func() error {
-		return kdb.PeriodicCleanup(ctx, kdatabase.CleanupStmt{
-			Table:  "event",
-			PK:     "uuid",
-			Column: "created",
-		})
-	})
+	cleanup(kdatabase.CleanupStmt{
+		Table:  "container_log",
+		PK:     "container_uuid",
+		Column: "last_update",
+	}, retentions["container_log"])
 
-	g.Go(func() error {
-		return kdb.PeriodicCleanup(ctx, kdatabase.CleanupStmt{
-			Table:  "prometheus_cluster_metric",
-			PK:     "(cluster_uuid, timestamp, category, name)",
-			Column: "timestamp",
-		})
-	})
+	for _, table := range metrics.MetricTables {
+		pk := fmt.Sprintf("(%s, timestamp, category, name)", table.IdColumn)
 
-	g.Go(func() error {
-		return kdb.PeriodicCleanup(ctx, kdatabase.CleanupStmt{
-			Table:  "prometheus_node_metric",
-			PK:     "(node_uuid, timestamp, category, name)",
+		cleanup(kdatabase.CleanupStmt{
+			Table:  table.Name,
+			PK:     pk,
 			Column: "timestamp",
-		})
-	})
+		}, retentions[table.Name])
+
+		for _, rollup := range metrics.Rollups {
+			cleanup(kdatabase.CleanupStmt{
+				Table:  rollup.Table(table),
+				PK:     pk,
+				Column: "timestamp",
+			}, retentions[rollup.Table(table)])
+		}
+	}
 
-	g.Go(func() error {
-		return kdb.PeriodicCleanup(ctx, kdatabase.CleanupStmt{
-			Table:  "prometheus_pod_metric",
-			PK:     "(pod_uuid, timestamp, category, name)",
-			Column: "timestamp",
-		})
+	rollupSync := metrics.NewRollupSync(db, logs.GetChildLogger("rollups"), func(table string) time.Duration {
+		return retentions[table]
 	})
-
 	g.Go(func() error {
-		return kdb.PeriodicCleanup(ctx, kdatabase.CleanupStmt{
-			Table:  "prometheus_container_metric",
-			PK:     "(container_uuid, timestamp, category, name)",
-			Column: "timestamp",
-		})
+		return rollupSync.Run(ctx)
 	})
 
+	started()
+
 	if err := g.Wait(); err != nil {
 		klog.Fatal(err)
 	}
 }
 
-// dbHasSchema queries via db whether the database dbName has a table named "kubernetes_schema".
-func dbHasSchema(db *kdatabase.Database, dbName string) (bool, error) {
-	rows, err := db.Query(
-		db.Rebind("SELECT 1 FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA=? AND TABLE_NAME='kubernetes_schema'"),
-		dbName,
-	)
+// customResourceEventsChannels receives the upserted resources and the UUIDs of the deleted resources
+// of a custom resource.
+type customResourceEventsChannels struct {
+	upserts chan any
+	deletes chan any
+}
+
+// isNamespaced returns whether the given resource is namespaced according to the discovery API.
+func isNamespaced(clientset *kubernetes.Clientset, gvr kschema.GroupVersionResource) (bool, error) {
+	list, err := clientset.Discovery().ServerResourcesForGroupVersion(gvr.GroupVersion().String())
 	if err != nil {
-		return false, err
+		return false, errors.Wrapf(err, "cannot discover resources of %s", gvr.GroupVersion())
 	}
 
-	defer func() { _ = rows.Close() }()
+	for _, resource := range list.APIResources {
+		if resource.Name == gvr.Resource {
+			return resource.Namespaced, nil
+		}
+	}
 
-	return rows.Next(), rows.Err()
+	return false, errors.Errorf("resource %q not found in %s", gvr.Resource, gvr.GroupVersion())
 }
 
-func SyncServicePods(ctx context.Context, db *kdatabase.Database, serviceList v2.ServiceInformer, podList v2.PodInformer) error {
+// SyncServicePods synchronizes which pods are selected by which services.
+// The given informers may each be restricted to a single namespace.
+// Pods are only matched against services of the same namespace.
+func SyncServicePods(
+	ctx context.Context,
+	db *kdatabase.Database,
+	serviceInformers []v2.ServiceInformer,
+	podInformers []v2.PodInformer,
+	upsertPods, deletePods, upsertServices <-chan any,
+	reconcileInterval time.Duration,
+) error {
 	servicePods := make(chan any)
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -728,18 +1002,36 @@ func SyncServicePods(ctx context.Context, db *kdatabase.Database, serviceList v2
 		return db.UpsertStreamed(ctx, servicePods)
 	})
 
+	if reconcileInterval > 0 {
+		g.Go(func() error {
+			defer periodic.Start(ctx, reconcileInterval, func(periodic.Tick) {
+				if err := reconcileServicePods(ctx, db, serviceInformers, podInformers, servicePods); err != nil {
+					klog.Errorf("Cannot reconcile service pods: %v", err)
+				}
+			}).Stop()
+
+			<-ctx.Done()
+
+			return ctx.Err()
+		})
+	}
+
 	g.Go(func() error {
-		ch := cachev1.Multiplexers().Pods().UpsertEvents().Out()
 		for {
 			select {
-			case pod, more := <-ch:
+			case pod, more := <-upsertPods:
 				if !more {
 					return nil
 				}
 
-				services, err := serviceList.Lister().List(labels.NewSelector())
-				if err != nil {
-					return err
+				var services []*kcorev1.Service
+				for _, serviceList := range serviceInformers {
+					s, err := serviceList.Lister().Services(pod.(*schemav1.Pod).Namespace).List(labels.Everything())
+					if err != nil {
+						return err
+					}
+
+					services = append(services, s...)
 				}
 
 				podLabels := make(labels.Set)
@@ -776,10 +1068,9 @@ func SyncServicePods(ctx context.Context, db *kdatabase.Database, serviceList v2
 	})
 
 	g.Go(func() error {
-		ch := cachev1.Multiplexers().Services().UpsertEvents().Out()
 		for {
 			select {
-			case service, more := <-ch:
+			case service, more := <-upsertServices:
 				if !more {
 					return nil
 				}
@@ -798,9 +1089,14 @@ func SyncServicePods(ctx context.Context, db *kdatabase.Database, serviceList v2
 					return err
 				}
 
-				pods, err := podList.Lister().List(selector)
-				if err != nil {
-					return err
+				var pods []*kcorev1.Pod
+				for _, podList := range podInformers {
+					p, err := podList.Lister().Pods(service.(*schemav1.Service).Namespace).List(selector)
+					if err != nil {
+						return err
+					}
+
+					pods = append(pods, p...)
 				}
 
 				for _, pod := range pods {
@@ -820,15 +1116,14 @@ func SyncServicePods(ctx context.Context, db *kdatabase.Database, serviceList v2
 	})
 
 	g.Go(func() error {
-		ch := cachev1.Multiplexers().Pods().DeleteEvents().Out()
 		for {
 			select {
-			case podUuid, more := <-ch:
+			case podUuid, more := <-deletePods:
 				if !more {
 					return nil
 				}
 
-				_, err := db.ExecContext(ctx, `DELETE FROM service_pod WHERE pod_uuid = ?`, podUuid)
+				_, err := db.ExecContext(ctx, db.Rebind(`DELETE FROM service_pod WHERE pod_uuid = ?`), podUuid)
 				if err != nil {
 					return err
 				}
@@ -840,3 +1135,101 @@ func SyncServicePods(ctx context.Context, db *kdatabase.Database, serviceList v2
 
 	return g.Wait()
 }
+
+// reconcileServicePods computes which pods are selected by which services from the informer caches and
+// compares the result with the service_pod rows of the services in the caches.
+// Rows whose pods are no longer selected are deleted and missing rows are sent to servicePods.
+func reconcileServicePods(
+	ctx context.Context,
+	db *kdatabase.Database,
+	serviceInformers []v2.ServiceInformer,
+	podInformers []v2.PodInformer,
+	servicePods chan<- any,
+) error {
+	for _, informer := range serviceInformers {
+		if !informer.Informer().HasSynced() {
+			return nil
+		}
+	}
+
+	for _, informer := range podInformers {
+		if !informer.Informer().HasSynced() {
+			return nil
+		}
+	}
+
+	known := make(map[types.UUID]struct{})
+	expected := make(map[schemav1.ServicePod]struct{})
+	for _, serviceInformer := range serviceInformers {
+		services, err := serviceInformer.Lister().List(labels.Everything())
+		if err != nil {
+			return err
+		}
+
+		for _, service := range services {
+			serviceUuid := schemav1.EnsureUUID(service.UID)
+			known[serviceUuid] = struct{}{}
+
+			if len(service.Spec.Selector) == 0 {
+				continue
+			}
+
+			selector := labels.SelectorFromSet(service.Spec.Selector)
+			for _, podInformer := range podInformers {
+				pods, err := podInformer.Lister().Pods(service.Namespace).List(selector)
+				if err != nil {
+					return err
+				}
+
+				for _, pod := range pods {
+					expected[schemav1.ServicePod{ServiceUuid: serviceUuid, PodUuid: schemav1.EnsureUUID(pod.UID)}] = struct{}{}
+				}
+			}
+		}
+	}
+
+	query := "SELECT sp.service_uuid, sp.pod_uuid FROM service_pod sp" +
+		" INNER JOIN service s ON s.uuid = sp.service_uuid WHERE s.cluster_uuid = ?"
+	var rows []schemav1.ServicePod
+	if err := db.SelectContext(ctx, &rows, db.Rebind(query), cluster.ClusterUuidFromContext(ctx)); err != nil {
+		return kdatabase.CantPerformQuery(err, query)
+	}
+
+	var orphaned, missing int
+	for _, row := range rows {
+		if _, ok := expected[row]; ok {
+			delete(expected, row)
+
+			continue
+		}
+
+		// Rows of services not in the caches are deleted along with their service.
+		if _, ok := known[row.ServiceUuid]; !ok {
+			continue
+		}
+
+		stmt := db.Rebind("DELETE FROM service_pod WHERE service_uuid = ? AND pod_uuid = ?")
+		if _, err := db.ExecContext(ctx, stmt, row.ServiceUuid, row.PodUuid); err != nil {
+			return kdatabase.CantPerformQuery(err, stmt)
+		}
+		orphaned++
+	}
+
+	for servicePod := range expected {
+		select {
+		case servicePods <- servicePod:
+			missing++
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	telemetry.ReconcileDrift.WithLabelValues("service_pod", "orphaned").Add(float64(orphaned))
+	telemetry.ReconcileDrift.WithLabelValues("service_pod", "missing").Add(float64(missing))
+
+	if orphaned+missing > 0 {
+		klog.Infof("Reconciled drift of service pods (orphaned: %d, missing: %d)", orphaned, missing)
+	}
+
+	return nil
+}
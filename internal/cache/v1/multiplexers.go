@@ -13,9 +13,13 @@ type EventsMultiplexer interface {
 }
 
 type EventsMultiplexers interface {
+	CronJobs() EventsMultiplexer
 	DaemonSets() EventsMultiplexer
 	Deployments() EventsMultiplexer
+	Jobs() EventsMultiplexer
 	Nodes() EventsMultiplexer
+	PersistentVolumeClaims() EventsMultiplexer
+	PersistentVolumes() EventsMultiplexer
 	Pods() EventsMultiplexer
 	ReplicaSets() EventsMultiplexer
 	Services() EventsMultiplexer
@@ -55,13 +59,21 @@ func (e events) Run(ctx context.Context) error {
 }
 
 type multiplexers struct {
-	daemonSets   events
-	deployments  events
-	nodes        events
-	pods         events
-	replicaSets  events
-	services     events
-	statefulSets events
+	cronJobs               events
+	daemonSets             events
+	deployments            events
+	jobs                   events
+	nodes                  events
+	persistentVolumeClaims events
+	persistentVolumes      events
+	pods                   events
+	replicaSets            events
+	services               events
+	statefulSets           events
+}
+
+func (m multiplexers) CronJobs() EventsMultiplexer {
+	return m.cronJobs
 }
 
 func (m multiplexers) DaemonSets() EventsMultiplexer {
@@ -72,10 +84,22 @@ func (m multiplexers) Deployments() EventsMultiplexer {
 	return m.deployments
 }
 
+func (m multiplexers) Jobs() EventsMultiplexer {
+	return m.jobs
+}
+
 func (m multiplexers) Nodes() EventsMultiplexer {
 	return m.nodes
 }
 
+func (m multiplexers) PersistentVolumeClaims() EventsMultiplexer {
+	return m.persistentVolumeClaims
+}
+
+func (m multiplexers) PersistentVolumes() EventsMultiplexer {
+	return m.persistentVolumes
+}
+
 func (m multiplexers) Pods() EventsMultiplexer {
 	return m.pods
 }
@@ -95,6 +119,10 @@ func (m multiplexers) StatefulSets() EventsMultiplexer {
 func (m multiplexers) Run(ctx context.Context) error {
 	g, ctx := errgroup.WithContext(ctx)
 
+	g.Go(func() error {
+		return m.cronJobs.Run(ctx)
+	})
+
 	g.Go(func() error {
 		return m.daemonSets.Run(ctx)
 	})
@@ -103,10 +131,22 @@ func (m multiplexers) Run(ctx context.Context) error {
 		return m.deployments.Run(ctx)
 	})
 
+	g.Go(func() error {
+		return m.jobs.Run(ctx)
+	})
+
 	g.Go(func() error {
 		return m.nodes.Run(ctx)
 	})
 
+	g.Go(func() error {
+		return m.persistentVolumeClaims.Run(ctx)
+	})
+
+	g.Go(func() error {
+		return m.persistentVolumes.Run(ctx)
+	})
+
 	g.Go(func() error {
 		return m.pods.Run(ctx)
 	})
@@ -130,6 +170,10 @@ var m multiplexers
 
 func init() {
 	m = multiplexers{
+		cronJobs: events{
+			upsertEvents: internal.NewChannelMux[any](),
+			deleteEvents: internal.NewChannelMux[any](),
+		},
 		daemonSets: events{
 			upsertEvents: internal.NewChannelMux[any](),
 			deleteEvents: internal.NewChannelMux[any](),
@@ -138,10 +182,22 @@ func init() {
 			upsertEvents: internal.NewChannelMux[any](),
 			deleteEvents: internal.NewChannelMux[any](),
 		},
+		jobs: events{
+			upsertEvents: internal.NewChannelMux[any](),
+			deleteEvents: internal.NewChannelMux[any](),
+		},
 		nodes: events{
 			upsertEvents: internal.NewChannelMux[any](),
 			deleteEvents: internal.NewChannelMux[any](),
 		},
+		persistentVolumeClaims: events{
+			upsertEvents: internal.NewChannelMux[any](),
+			deleteEvents: internal.NewChannelMux[any](),
+		},
+		persistentVolumes: events{
+			upsertEvents: internal.NewChannelMux[any](),
+			deleteEvents: internal.NewChannelMux[any](),
+		},
 		pods: events{
 			upsertEvents: internal.NewChannelMux[any](),
 			deleteEvents: internal.NewChannelMux[any](),
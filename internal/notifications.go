@@ -2,6 +2,7 @@ package internal
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"github.com/icinga/icinga-go-library/database"
 	"github.com/icinga/icinga-go-library/types"
@@ -30,21 +31,26 @@ func SyncNotificationsConfig(ctx context.Context, db *database.DB, config *notif
 					Locked:      _true,
 				})
 			} else {
-				if err := tx.SelectContext(ctx, &config.KubernetesWebUrl, fmt.Sprintf(
-					`SELECT "%s" FROM "%s"`,
+				if err := tx.GetContext(
+					ctx,
+					&config.KubernetesWebUrl,
+					tx.Rebind(fmt.Sprintf(
+						`SELECT "value" FROM "%s" WHERE "cluster_uuid" = ? AND "key" = ?`,
+						database.TableName(schemav1.Config{}),
+					)),
+					clusterUuid,
 					schemav1.ConfigKeyNotificationsKubernetesWebUrl,
-					database.TableName(schemav1.Config{})),
-				); err != nil {
+				); err != nil && !errors.Is(err, sql.ErrNoRows) {
 					return errors.Wrap(err, "cannot select Icinga Notifications config")
 				}
 			}
 
 			if _, err := tx.ExecContext(
 				ctx,
-				fmt.Sprintf(
+				tx.Rebind(fmt.Sprintf(
 					`DELETE FROM "%s" WHERE "cluster_uuid" = ? AND "key" LIKE ? AND "locked" = ?`,
 					database.TableName(&schemav1.Config{}),
-				),
+				)),
 				clusterUuid,
 				`notifications.%`,
 				_true,
@@ -66,10 +72,10 @@ func SyncNotificationsConfig(ctx context.Context, db *database.DB, config *notif
 		err := db.ExecTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
 			if _, err := tx.ExecContext(
 				ctx,
-				fmt.Sprintf(
+				tx.Rebind(fmt.Sprintf(
 					`DELETE FROM "%s" WHERE "cluster_uuid" = ? AND "key" LIKE ? AND "locked" = ?`,
 					database.TableName(&schemav1.Config{}),
-				),
+				)),
 				clusterUuid,
 				`notifications.%`,
 				_true,
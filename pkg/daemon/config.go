@@ -3,8 +3,15 @@ package daemon
 import (
 	"github.com/icinga/icinga-go-library/database"
 	"github.com/icinga/icinga-go-library/logging"
+	kdatabase "github.com/icinga/icinga-kubernetes/pkg/database"
+	"github.com/icinga/icinga-kubernetes/pkg/downtime"
+	"github.com/icinga/icinga-kubernetes/pkg/leaderelection"
 	"github.com/icinga/icinga-kubernetes/pkg/metrics"
 	"github.com/icinga/icinga-kubernetes/pkg/notifications"
+	"github.com/icinga/icinga-kubernetes/pkg/resources"
+	"github.com/icinga/icinga-kubernetes/pkg/scope"
+	syncv1 "github.com/icinga/icinga-kubernetes/pkg/sync/v1"
+	"github.com/icinga/icinga-kubernetes/pkg/telemetry"
 )
 
 // DefaultConfigPath specifies the default location of Icinga for Kubernetes's config.yml
@@ -13,10 +20,19 @@ const DefaultConfigPath = "./config.yml"
 
 // Config defines Icinga Kubernetes config.
 type Config struct {
-	Database      database.Config          `yaml:"database" envPrefix:"DATABASE_"`
-	Logging       logging.Config           `yaml:"logging" envPrefix:"LOGGING_"`
-	Notifications notifications.Config     `yaml:"notifications" envPrefix:"NOTIFICATIONS_"`
-	Prometheus    metrics.PrometheusConfig `yaml:"prometheus" envPrefix:"PROMETHEUS_"`
+	Database        database.Config           `yaml:"database" envPrefix:"DATABASE_"`
+	Logging         logging.Config            `yaml:"logging" envPrefix:"LOGGING_"`
+	Notifications   notifications.Config      `yaml:"notifications" envPrefix:"NOTIFICATIONS_"`
+	Prometheus      metrics.PrometheusConfig  `yaml:"prometheus" envPrefix:"PROMETHEUS_"`
+	Kubelet         metrics.KubeletConfig     `yaml:"kubelet" envPrefix:"KUBELET_"`
+	LeaderElection  leaderelection.Config     `yaml:"leader_election" envPrefix:"LEADER_ELECTION_"`
+	Telemetry       telemetry.Config          `yaml:"telemetry" envPrefix:"TELEMETRY_"`
+	Scope           scope.Config              `yaml:"scope" envPrefix:"SCOPE_"`
+	Resources       resources.Config          `yaml:"resources"`
+	CustomResources resources.CustomResources `yaml:"custom_resources"`
+	Sync            syncv1.Config             `yaml:"sync" envPrefix:"SYNC_"`
+	Downtimes       downtime.Config           `yaml:"downtimes"`
+	Retention       kdatabase.RetentionConfig `yaml:"retention" envPrefix:"RETENTION_"`
 }
 
 // Validate checks constraints in the supplied configuration and returns an error if they are violated.
@@ -33,6 +49,42 @@ func (c *Config) Validate() error {
 		return err
 	}
 
+	if err := c.Kubelet.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.LeaderElection.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Telemetry.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Scope.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Resources.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.CustomResources.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Sync.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Downtimes.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Retention.Validate(); err != nil {
+		return err
+	}
+
 	return c.Notifications.Validate()
 }
 
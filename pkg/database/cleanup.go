@@ -8,6 +8,8 @@ import (
 	"github.com/icinga/icinga-go-library/periodic"
 	"github.com/icinga/icinga-go-library/retry"
 	"github.com/icinga/icinga-go-library/types"
+	"github.com/icinga/icinga-kubernetes/pkg/telemetry"
+	"strings"
 	"time"
 )
 
@@ -24,8 +26,9 @@ func (stmt *CleanupStmt) Build(driverName string, limit uint64) string {
 	case MySQL, "mysql":
 		return fmt.Sprintf(`DELETE FROM %[1]s WHERE %[2]s < :time LIMIT %[3]d`, stmt.Table, stmt.Column, limit)
 	case PostgreSQL, "postgres":
+		// PK may be a parenthesized list of columns for composite primary keys.
 		return fmt.Sprintf(`WITH rows AS (SELECT %[1]s FROM %[2]s WHERE %[3]s < :time LIMIT %[4]d)
-DELETE FROM %[2]s WHERE %[1]s IN (SELECT %[1]s FROM rows)`, stmt.PK, stmt.Table, stmt.Column, limit)
+DELETE FROM %[2]s WHERE (%[1]s) IN (SELECT %[1]s FROM rows)`, strings.Trim(stmt.PK, "()"), stmt.Table, stmt.Column, limit)
 	default:
 		panic(fmt.Sprintf("invalid database type %s", driverName))
 	}
@@ -83,6 +86,7 @@ func (db *Database) CleanupOlderThan(
 		}
 
 		counter.Add(uint64(rowsDeleted))
+		telemetry.CleanupRowsDeleted.WithLabelValues(stmt.Table).Add(float64(rowsDeleted))
 
 		for _, onSuccess := range onSuccess {
 			if err := onSuccess(ctx, make([]struct{}, rowsDeleted)); err != nil {
@@ -102,15 +106,19 @@ type cleanupWhere struct {
 	Time types.UnixMilli
 }
 
-func (db *Database) PeriodicCleanup(ctx context.Context, stmt CleanupStmt) error {
+// PeriodicCleanup deletes the rows older than the given retention with the specified statement
+// in the given interval. Deletes a maximum of batchSize rows per statement.
+func (db *Database) PeriodicCleanup(
+	ctx context.Context, stmt CleanupStmt, retention, interval time.Duration, batchSize uint64,
+) error {
 	errs := make(chan error, 1)
 	defer close(errs)
 
-	periodic.Start(ctx, time.Hour, func(tick periodic.Tick) {
-		olderThan := tick.Time.AddDate(0, 0, -1)
+	periodic.Start(ctx, interval, func(tick periodic.Tick) {
+		olderThan := tick.Time.Add(-retention)
 
 		_, err := db.CleanupOlderThan(
-			ctx, stmt, 5000, olderThan,
+			ctx, stmt, batchSize, olderThan,
 		)
 
 		if err != nil {
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// RetentionConfig defines how long rows of continuously growing tables are kept.
+// The cleanup of a table is disabled if its retention is zero.
+type RetentionConfig struct {
+	// Interval is the interval in which old rows are deleted.
+	Interval time.Duration `yaml:"interval" env:"INTERVAL" default:"1h"`
+	// BatchSize is the maximum number of rows deleted per statement.
+	BatchSize uint64 `yaml:"batch_size" env:"BATCH_SIZE" default:"5000"`
+
+	Event                     time.Duration `yaml:"event" env:"EVENT" default:"24h"`
+	ContainerLog              time.Duration `yaml:"container_log" env:"CONTAINER_LOG"`
+	PrometheusClusterMetric   time.Duration `yaml:"prometheus_cluster_metric" env:"PROMETHEUS_CLUSTER_METRIC" default:"24h"`
+	PrometheusNodeMetric      time.Duration `yaml:"prometheus_node_metric" env:"PROMETHEUS_NODE_METRIC" default:"24h"`
+	PrometheusPodMetric       time.Duration `yaml:"prometheus_pod_metric" env:"PROMETHEUS_POD_METRIC" default:"24h"`
+	PrometheusContainerMetric time.Duration `yaml:"prometheus_container_metric" env:"PROMETHEUS_CONTAINER_METRIC" default:"24h"`
+	PvcMetric                 time.Duration `yaml:"pvc_metric" env:"PVC_METRIC" default:"24h"`
+	// Metric5m and Metric1h apply to all tables of metrics rolled up per 5 minutes and per hour, respectively.
+	Metric5m time.Duration `yaml:"metric_5m" env:"METRIC_5M" default:"168h"`
+	Metric1h time.Duration `yaml:"metric_1h" env:"METRIC_1H" default:"2160h"`
+}
+
+// Tables returns the retention per table, except for the tables of rolled up metrics.
+func (c *RetentionConfig) Tables() map[string]time.Duration {
+	return map[string]time.Duration{
+		"event":                       c.Event,
+		"container_log":               c.ContainerLog,
+		"prometheus_cluster_metric":   c.PrometheusClusterMetric,
+		"prometheus_node_metric":      c.PrometheusNodeMetric,
+		"prometheus_pod_metric":       c.PrometheusPodMetric,
+		"prometheus_container_metric": c.PrometheusContainerMetric,
+		"pvc_metric":                  c.PvcMetric,
+	}
+}
+
+// Validate checks constraints in the supplied retention configuration and returns an error if they are violated.
+func (c *RetentionConfig) Validate() error {
+	if c.Interval <= 0 {
+		return errors.New("'interval' must be positive")
+	}
+
+	if c.BatchSize == 0 {
+		return errors.New("'batch_size' must be positive")
+	}
+
+	for table, retention := range c.Tables() {
+		if retention < 0 {
+			return errors.Errorf("retention of %q must not be negative", table)
+		}
+	}
+
+	if c.Metric5m < 0 || c.Metric1h < 0 {
+		return errors.New("'metric_5m' and 'metric_1h' must not be negative")
+	}
+
+	return nil
+}
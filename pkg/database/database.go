@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -39,6 +40,9 @@ type Database struct {
 
 	tableSemaphores   map[string]*semaphore.Weighted
 	tableSemaphoresMu sync.Mutex
+
+	// primaryKeys caches the primary key columns per table, see primaryKey.
+	primaryKeys sync.Map
 }
 
 // NewFromSqlxDb returns a new Database connection from the given sqlx.DB.
@@ -189,6 +193,7 @@ func (db *Database) BulkExec(
 								return errors.Wrapf(err, "cannot build placeholders for %q", query)
 							}
 
+							started := time.Now()
 							stmt = db.Rebind(stmt)
 							_, err = db.ExecContext(ctx, stmt, args...)
 							if err != nil {
@@ -196,6 +201,7 @@ func (db *Database) BulkExec(
 							}
 
 							counter.Add(uint64(len(b)))
+							f.observe(len(b), started)
 
 							if f.onSuccess != nil {
 								if err := f.onSuccess(ctx, b); err != nil {
@@ -271,12 +277,14 @@ func (db *Database) NamedBulkExec(
 						return retry.WithBackoff(
 							ctx,
 							func(ctx context.Context) error {
+								started := time.Now()
 								_, err := db.NamedExecContext(ctx, query, b)
 								if err != nil {
 									return CantPerformQuery(err, query)
 								}
 
 								counter.Add(uint64(len(b)))
+								with.observe(len(b), started)
 
 								if with.onSuccess != nil {
 									if err := with.onSuccess(ctx, b); err != nil {
@@ -323,6 +331,7 @@ func (db *Database) GetSemaphoreForTable(table string) *semaphore.Weighted {
 func (db *Database) DeleteStreamed(
 	ctx context.Context, from interface{}, ids <-chan interface{}, features ...Feature,
 ) error {
+	features = append(slices.Clip(features), withTelemetry(TableName(from), "delete"))
 	f := NewFeatures(features...)
 
 	if relations, ok := from.(HasRelations); ok && f.cascading {
@@ -431,6 +440,7 @@ func (db *Database) DeleteStreamed(
 // The upsert statement is created using BuildUpsertStmt with the first entity from the entities stream.
 // Bulk size is controlled via Options.MaxPlaceholdersPerStatement and
 // concurrency is controlled via Options.MaxConnectionsPerTable.
+// With cascading, the relations of the entities are upserted along with them, see UpsertWithRelations.
 func (db *Database) UpsertStreamed(
 	ctx context.Context, entities <-chan interface{}, features ...Feature,
 ) error {
@@ -441,93 +451,329 @@ func (db *Database) UpsertStreamed(
 
 	sem := db.GetSemaphoreForTable(TableName(first))
 	stmt, placeholders := db.BuildUpsertStmt(first)
+	features = append(slices.Clip(features), withTelemetry(TableName(first), "upsert"))
 	with := NewFeatures(features...)
 
-	if relations, ok := first.(HasRelations); ok && with.cascading {
-		var g *errgroup.Group
-		g, ctx = errgroup.WithContext(ctx)
-		streams := make(map[string]chan interface{}, len(relations.Relations()))
-		for _, relation := range relations.Relations() {
-			relation := relation
+	if _, ok := first.(HasRelations); ok && with.cascading {
+		return db.UpsertWithRelations(ctx, stmt, db.BatchSizeByPlaceholders(placeholders), sem, forward, features...)
+	}
 
-			ch := make(chan interface{})
-			g.Go(func() error {
-				defer runtime.HandleCrash()
-				defer close(ch)
+	return db.NamedBulkExec(
+		ctx, stmt, db.BatchSizeByPlaceholders(placeholders), sem, forward, com.NeverSplit[any], features...)
+}
 
-				return db.UpsertStreamed(ctx, ch, WithCascading())
-			})
-			streams[TableName(relation)] = ch
-		}
+// UpsertWithRelations bulk upserts entities like NamedBulkExec, but also upserts their relations.
+// Each bulk is upserted in a single transaction, which also deletes rows of relations
+// that are no longer part of the upserted entities, see syncRelations.
+// Entities for which the transaction succeeded will be passed to onSuccess.
+func (db *Database) UpsertWithRelations(
+	ctx context.Context, query string, count int, sem *semaphore.Weighted, arg <-chan interface{},
+	features ...Feature,
+) error {
+	g, ctx := errgroup.WithContext(ctx)
 
-		source := forward
-		forward := make(chan interface{})
-		dup := make(chan interface{})
+	var counter com.Counter
+	defer db.periodicLog(ctx, query, &counter).Stop()
 
-		g.Go(func() error {
-			defer close(forward)
-			defer close(dup)
+	bulk := com.Bulk(ctx, arg, count, com.NeverSplit[any])
+	with := NewFeatures(features...)
 
-			for {
-				select {
-				case entity, more := <-source:
-					if !more {
-						return nil
-					}
+	g.Go(func() error {
+		defer runtime.HandleCrash()
 
-					select {
-					case forward <- entity:
-					case <-ctx.Done():
-						return ctx.Err()
-					}
+		for {
+			select {
+			case b, ok := <-bulk:
+				if !ok {
+					return nil
+				}
 
-					select {
-					case dup <- entity:
-					case <-ctx.Done():
-						return ctx.Err()
-					}
-				case <-ctx.Done():
-					return ctx.Err()
+				if err := sem.Acquire(ctx, 1); err != nil {
+					return errors.Wrap(err, "cannot acquire semaphore")
 				}
-			}
-		})
 
-		g.Go(func() error {
-			defer runtime.HandleCrash()
+				g.Go(func(b []interface{}) func() error {
+					return func() error {
+						defer runtime.HandleCrash()
+						defer sem.Release(1)
+
+						err := retry.WithBackoff(
+							ctx,
+							func(ctx context.Context) error {
+								started := time.Now()
+								tx, err := db.BeginTxx(ctx, nil)
+								if err != nil {
+									return errors.Wrap(err, "cannot start transaction")
+								}
+								defer func() { _ = tx.Rollback() }()
+
+								if _, err := tx.NamedExecContext(ctx, query, b); err != nil {
+									return CantPerformQuery(err, query)
+								}
+
+								if err := db.syncRelations(ctx, tx, b); err != nil {
+									return err
+								}
+
+								if err := tx.Commit(); err != nil {
+									return errors.Wrap(err, "cannot commit transaction")
+								}
+
+								counter.Add(uint64(len(b)))
+								with.observe(len(b), started)
+
+								return nil
+							},
+							IsRetryable,
+							backoff.NewExponentialWithJitter(1*time.Millisecond, 1*time.Second),
+							retry.Settings{},
+						)
+						if err != nil {
+							return err
+						}
+
+						// Not part of the retried function, as the transaction must not be repeated once committed.
+						if with.onSuccess != nil {
+							return with.onSuccess(ctx, b)
+						}
 
-			for {
-				select {
-				case entity, more := <-dup:
-					if !more {
 						return nil
 					}
+				}(b))
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	})
 
-					for _, relation := range entity.(HasRelations).Relations() {
-						relation := relation
-						g.Go(func() error {
-							defer runtime.HandleCrash()
+	return g.Wait()
+}
 
-							return relation.StreamInto(ctx, streams[TableName(relation)])
-						})
-					}
-				case <-ctx.Done():
-					return ctx.Err()
+// syncRelations upserts the relations of the given entities within tx.
+// Before that, rows of relations that have StaleDelete enabled and are no longer part of the given entities
+// are deleted, including their own relations, see deleteStale.
+// Relations of relations are synced recursively.
+func (db *Database) syncRelations(ctx context.Context, tx *sqlx.Tx, entities []interface{}) error {
+	type relationEntities struct {
+		relation Relation
+		entities []interface{}
+	}
+
+	var relations []*relationEntities
+	byKey := make(map[string]*relationEntities)
+	ids := make([]interface{}, 0, len(entities))
+
+	for _, entity := range entities {
+		hr, ok := entity.(HasRelations)
+		if !ok {
+			continue
+		}
+
+		id, err := db.columnOf(entity, "uuid")
+		if err != nil {
+			return err
+		}
+		ids = append(ids, id)
+
+		for _, relation := range hr.Relations() {
+			key := TableName(relation) + "." + relation.ForeignKey()
+			re, ok := byKey[key]
+			if !ok {
+				re = &relationEntities{relation: relation}
+				byKey[key] = re
+				relations = append(relations, re)
+			}
+
+			re.entities = append(re.entities, relation.Entities()...)
+		}
+	}
+
+	for _, re := range relations {
+		if re.relation.StaleDelete() {
+			if err := db.deleteStale(ctx, tx, re.relation, ids, re.entities); err != nil {
+				return err
+			}
+		}
+
+		if len(re.entities) == 0 {
+			continue
+		}
+
+		stmt, placeholders := db.BuildUpsertStmt(re.entities[0])
+		for chunk := range slices.Chunk(re.entities, db.BatchSizeByPlaceholders(placeholders)) {
+			if _, err := tx.NamedExecContext(ctx, stmt, chunk); err != nil {
+				return CantPerformQuery(err, stmt)
+			}
+		}
+
+		if _, ok := re.entities[0].(HasRelations); ok {
+			if err := db.syncRelations(ctx, tx, re.entities); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// deleteStale deletes the rows of the given relation within tx that belong to the entities with the given ids,
+// but whose primary key is not among the given current entities of the relation.
+// Rows that are still current are left untouched. The relations of deleted rows are deleted in cascade.
+func (db *Database) deleteStale(
+	ctx context.Context, tx *sqlx.Tx, relation Relation, ids []interface{}, current []interface{},
+) error {
+	table := TableName(relation)
+	pk, err := db.primaryKey(ctx, table)
+	if err != nil {
+		return err
+	}
+
+	keysByParent := make(map[string][]interface{}, len(ids))
+	for _, entity := range current {
+		parent, err := db.columnOf(entity, relation.ForeignKey())
+		if err != nil {
+			return err
+		}
+
+		for _, column := range pk {
+			v, err := db.columnOf(entity, column)
+			if err != nil {
+				return err
+			}
+
+			keysByParent[fmt.Sprint(parent)] = append(keysByParent[fmt.Sprint(parent)], v)
+		}
+	}
+
+	var parents, keys []interface{}
+	flush := func() error {
+		if len(parents) == 0 {
+			return nil
+		}
+
+		where := fmt.Sprintf(
+			"%s IN (%s)", db.QuoteIdentifier(relation.ForeignKey()), placeholderList(len(parents), 1))
+		args := append(slices.Clip(parents), keys...)
+		if len(keys) > 0 {
+			where += fmt.Sprintf(
+				" AND (%s) NOT IN (%s)", db.QuoteColumns(pk), placeholderList(len(keys)/len(pk), len(pk)))
+		}
+
+		parents, keys = nil, nil
+
+		return db.deleteCascading(ctx, tx, relation.Entity(), table, where, args)
+	}
+
+	for _, id := range ids {
+		parentKeys := keysByParent[fmt.Sprint(id)]
+		if len(parents) > 0 && len(parents)+1+len(keys)+len(parentKeys) > db.Options.MaxPlaceholdersPerStatement {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+
+		parents = append(parents, id)
+		keys = append(keys, parentKeys...)
+	}
+
+	return flush()
+}
+
+// deleteCascading deletes the rows of the given table that match the given condition within tx.
+// If the given entity of the table has relations, the rows of those relations that are deleted in cascade
+// and reference the deleted rows are deleted first, recursively.
+func (db *Database) deleteCascading(
+	ctx context.Context, tx *sqlx.Tx, entity interface{}, table, where string, args []interface{},
+) error {
+	if hr, ok := entity.(HasRelations); ok {
+		query := fmt.Sprintf("SELECT uuid FROM %s WHERE %s", db.QuoteIdentifier(table), where)
+
+		var uuids [][]byte
+		if err := tx.SelectContext(ctx, &uuids, tx.Rebind(query), args...); err != nil {
+			return CantPerformQuery(err, query)
+		}
+
+		for chunk := range slices.Chunk(uuids, db.Options.MaxPlaceholdersPerStatement) {
+			ids := make([]interface{}, 0, len(chunk))
+			for _, id := range chunk {
+				ids = append(ids, id)
+			}
+
+			for _, relation := range hr.Relations() {
+				if !relation.CascadeDelete() {
+					continue
+				}
+
+				if err := db.deleteCascading(
+					ctx, tx, relation.Entity(), TableName(relation),
+					fmt.Sprintf("%s IN (%s)", db.QuoteIdentifier(relation.ForeignKey()), placeholderList(len(ids), 1)),
+					ids,
+				); err != nil {
+					return err
 				}
 			}
-		})
+		}
+	}
 
-		g.Go(func() error {
-			defer runtime.HandleCrash()
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s", db.QuoteIdentifier(table), where)
+	if _, err := tx.ExecContext(ctx, tx.Rebind(stmt), args...); err != nil {
+		return CantPerformQuery(err, stmt)
+	}
 
-			return db.NamedBulkExec(
-				ctx, stmt, db.BatchSizeByPlaceholders(placeholders), sem, forward, com.NeverSplit[any], features...)
-		})
+	return nil
+}
 
-		return g.Wait()
+// primaryKey returns the columns of the primary key of the given table, which are cached after the first query.
+func (db *Database) primaryKey(ctx context.Context, table string) ([]string, error) {
+	if pk, ok := db.primaryKeys.Load(table); ok {
+		return pk.([]string), nil
 	}
 
-	return db.NamedBulkExec(
-		ctx, stmt, db.BatchSizeByPlaceholders(placeholders), sem, forward, com.NeverSplit[any], features...)
+	var query string
+	switch db.DriverName() {
+	case PostgreSQL:
+		query = `SELECT column_name FROM information_schema.key_column_usage` +
+			` WHERE table_schema = current_schema() AND table_name = ? AND constraint_name = 'pk_' || table_name` +
+			` ORDER BY ordinal_position`
+	default:
+		query = `SELECT column_name FROM information_schema.key_column_usage` +
+			` WHERE table_schema = DATABASE() AND table_name = ? AND constraint_name = 'PRIMARY'` +
+			` ORDER BY ordinal_position`
+	}
+
+	var pk []string
+	if err := db.SelectContext(ctx, &pk, db.Rebind(query), table); err != nil {
+		return nil, CantPerformQuery(err, query)
+	}
+
+	if len(pk) == 0 {
+		return nil, errors.Errorf("table %q has no primary key", table)
+	}
+
+	db.primaryKeys.Store(table, pk)
+
+	return pk, nil
+}
+
+// columnOf returns the value of the given column of the given entity.
+func (db *Database) columnOf(entity interface{}, column string) (interface{}, error) {
+	v := reflect.Indirect(reflect.ValueOf(entity))
+	fi, ok := db.Mapper.TypeMap(v.Type()).Names[column]
+	if !ok {
+		return nil, errors.Errorf("%T has no %s column", entity, column)
+	}
+
+	return reflectx.FieldByIndexes(v, fi.Index).Interface(), nil
+}
+
+// placeholderList returns n comma-separated placeholders, or row constructors of size placeholders if size > 1.
+func placeholderList(n, size int) string {
+	placeholder := "?"
+	if size > 1 {
+		placeholder = "(" + strings.TrimSuffix(strings.Repeat("?, ", size), ", ") + ")"
+	}
+
+	return strings.TrimSuffix(strings.Repeat(placeholder+", ", n), ", ")
 }
 
 // YieldAll executes the query with the supplied scope,
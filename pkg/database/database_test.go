@@ -0,0 +1,26 @@
+package database
+
+import "testing"
+
+func TestPlaceholderList(t *testing.T) {
+	tests := []struct {
+		name string
+		n    int
+		size int
+		want string
+	}{
+		{name: "empty", n: 0, size: 1, want: ""},
+		{name: "single", n: 1, size: 1, want: "?"},
+		{name: "multiple", n: 3, size: 1, want: "?, ?, ?"},
+		{name: "row", n: 1, size: 2, want: "(?, ?)"},
+		{name: "rows", n: 2, size: 3, want: "(?, ?, ?), (?, ?, ?)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := placeholderList(tt.n, tt.size); got != tt.want {
+				t.Errorf("placeholderList(%d, %d) = %q, want %q", tt.n, tt.size, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,7 +1,10 @@
 package database
 
 import (
+	"time"
+
 	"github.com/icinga/icinga-go-library/database"
+	"github.com/icinga/icinga-kubernetes/pkg/telemetry"
 )
 
 type Feature func(*Features)
@@ -10,6 +13,8 @@ type Features struct {
 	blocking  bool
 	cascading bool
 	onSuccess database.OnSuccess[any]
+	table     string
+	operation string
 }
 
 func NewFeatures(features ...Feature) *Features {
@@ -38,3 +43,21 @@ func WithOnSuccess(fn database.OnSuccess[any]) Feature {
 		f.onSuccess = fn
 	}
 }
+
+// withTelemetry records the number of rows and the duration of queries for the given table and operation.
+func withTelemetry(table, operation string) Feature {
+	return func(f *Features) {
+		f.table = table
+		f.operation = operation
+	}
+}
+
+// observe records the number of rows and the duration of a successful query if telemetry is enabled.
+func (f *Features) observe(rows int, started time.Time) {
+	if f.table == "" {
+		return
+	}
+
+	telemetry.DatabaseRows.WithLabelValues(f.table, f.operation).Add(float64(rows))
+	telemetry.DatabaseQueryDuration.WithLabelValues(f.table, f.operation).Observe(time.Since(started).Seconds())
+}
@@ -1,15 +1,17 @@
 package database
 
-import (
-	"context"
-)
+import "reflect"
 
 type Relation interface {
 	ForeignKey() string
 	SetForeignKey(fk string)
 	CascadeDelete() bool
 	WithoutCascadeDelete()
-	StreamInto(context.Context, chan interface{}) error
+	StaleDelete() bool
+	WithoutStaleDelete()
+	Entities() []interface{}
+	// Entity returns a new zero entity of the relation, e.g. to determine the relations of its rows.
+	Entity() interface{}
 	TableName() string
 }
 
@@ -31,9 +33,18 @@ func WithoutCascadeDelete() RelationOption {
 	}
 }
 
+// WithoutStaleDelete keeps rows of the relation that are not part of an upserted entity.
+// Use this for relations whose rows are managed elsewhere, e.g. favorites or container logs.
+func WithoutStaleDelete() RelationOption {
+	return func(r Relation) {
+		r.WithoutStaleDelete()
+	}
+}
+
 type relation[T comparable] struct {
 	foreignKey           string
 	withoutCascadeDelete bool
+	withoutStaleDelete   bool
 }
 
 func (r *relation[T]) ForeignKey() string {
@@ -52,6 +63,25 @@ func (r *relation[T]) WithoutCascadeDelete() {
 	r.withoutCascadeDelete = true
 }
 
+// StaleDelete returns whether rows of the relation that are no longer part of an upserted entity are deleted.
+// This only applies to relations that are also deleted in cascade.
+func (r *relation[T]) StaleDelete() bool {
+	return r.CascadeDelete() && !r.withoutStaleDelete
+}
+
+func (r *relation[T]) WithoutStaleDelete() {
+	r.withoutStaleDelete = true
+}
+
+func (r *relation[T]) Entity() interface{} {
+	t := reflect.TypeFor[T]()
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	return reflect.New(t).Interface()
+}
+
 func (r *relation[T]) TableName() string {
 	return TableName(*new(T))
 }
@@ -71,16 +101,13 @@ func HasMany[T comparable](entities []T, options ...RelationOption) Relation {
 	return r
 }
 
-func (r *hasMany[T]) StreamInto(ctx context.Context, ch chan interface{}) error {
+func (r *hasMany[T]) Entities() []interface{} {
+	entities := make([]interface{}, 0, len(r.entities))
 	for _, entity := range r.entities {
-		select {
-		case ch <- entity:
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+		entities = append(entities, entity)
 	}
 
-	return nil
+	return entities
 }
 
 type hasOne[T comparable] struct {
@@ -98,16 +125,12 @@ func HasOne[T comparable](entity T, options ...RelationOption) Relation {
 	return r
 }
 
-func (r *hasOne[T]) StreamInto(ctx context.Context, ch chan interface{}) error {
-	if r.entity != Zero[T]() {
-		select {
-		case ch <- r.entity:
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+func (r *hasOne[T]) Entities() []interface{} {
+	if r.entity == Zero[T]() {
+		return nil
 	}
 
-	return nil
+	return []interface{}{r.entity}
 }
 
 func Zero[T any]() T {
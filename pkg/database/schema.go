@@ -0,0 +1,370 @@
+package database
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/icinga/icinga-go-library/backoff"
+	"github.com/icinga/icinga-go-library/retry"
+	"github.com/icinga/icinga-go-library/types"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+// ErrSchemaTooNew is returned by MigrateSchema if the database schema is newer than the expected one.
+var ErrSchemaTooNew = errors.New("database schema is newer than expected")
+
+// ErrNoUpgradePath is returned by MigrateSchema if there are no upgrade scripts
+// that lead from the current to the expected schema version.
+var ErrNoUpgradePath = errors.New("no upgrade path for database schema")
+
+// ErrSchemaPartiallyUpgraded is returned by SchemaVersion if the most recent schema upgrade has not completed.
+// This can only happen with MySQL, which implicitly commits DDL statements, so that a failed upgrade step
+// cannot be rolled back. The remaining statements of the step must then be applied manually.
+var ErrSchemaPartiallyUpgraded = errors.New("database schema is partially upgraded")
+
+// Migration is a single schema upgrade script that upgrades the schema to Version.
+type Migration struct {
+	Version *version.Version
+	Script  string
+}
+
+// Migrations is a list of schema upgrade scripts ordered by version,
+// the first of which upgrades the schema from version Baseline.
+type Migrations struct {
+	Baseline *version.Version
+	Steps    []Migration
+}
+
+// LoadMigrations reads the upgrade scripts from the directory dir of fsys and returns them ordered by version.
+// Each script must be named after the schema version it upgrades to, e.g. 0.5.0.sql.
+// Files without the .sql extension are ignored. baseline is the oldest schema version the scripts apply to.
+func LoadMigrations(fsys fs.FS, dir string, baseline string) (*Migrations, error) {
+	b, err := version.ParseSemantic(baseline)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid baseline schema version %q", baseline)
+	}
+
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot read upgrade scripts from %q", dir)
+	}
+
+	migrations := &Migrations{Baseline: b}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".sql" {
+			continue
+		}
+
+		v, err := version.ParseSemantic(strings.TrimSuffix(name, ".sql"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid upgrade script name %q", name)
+		}
+
+		if !v.GreaterThan(b) {
+			return nil, errors.Errorf("upgrade script %q does not upgrade from baseline %s", name, baseline)
+		}
+
+		script, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot read upgrade script %q", name)
+		}
+
+		migrations.Steps = append(migrations.Steps, Migration{Version: v, Script: string(script)})
+	}
+
+	slices.SortFunc(migrations.Steps, func(a, b Migration) int {
+		switch {
+		case a.Version.LessThan(b.Version):
+			return -1
+		case a.Version.GreaterThan(b.Version):
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	return migrations, nil
+}
+
+// LockSchema acquires an advisory lock for the schema of the database dbName, blocking until it is available,
+// so that multiple replicas do not import, upgrade or drop the schema concurrently.
+// The lock is held by a dedicated connection, on which the returned unlock function releases it.
+func (db *Database) LockSchema(ctx context.Context, dbName string) (unlock func(), err error) {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot get connection")
+	}
+
+	var query, release string
+	var arg interface{}
+	switch db.DriverName() {
+	case PostgreSQL:
+		// Advisory locks are scoped to the database in PostgreSQL.
+		query = "SELECT pg_advisory_lock(?)"
+		release = "SELECT pg_advisory_unlock(?)"
+		h := fnv.New64a()
+		_, _ = h.Write([]byte("icinga-kubernetes-schema"))
+		arg = int64(h.Sum64())
+	default:
+		// Lock names are server-wide and limited to 64 characters in MySQL.
+		query = "SELECT GET_LOCK(?, -1)"
+		release = "SELECT RELEASE_LOCK(?)"
+		name := "icinga-kubernetes-schema:" + dbName
+		arg = name[:min(len(name), 64)]
+	}
+
+	if _, err := conn.ExecContext(ctx, db.Rebind(query), arg); err != nil {
+		_ = conn.Close()
+
+		return nil, CantPerformQuery(err, query)
+	}
+
+	return func() {
+		// Closing the connection only returns it to the pool, which would keep the session and thus the lock.
+		// If the lock cannot be released, the connection is discarded instead, which ends the session.
+		if _, err := conn.ExecContext(context.Background(), db.Rebind(release), arg); err != nil {
+			_ = conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+
+		_ = conn.Close()
+	}, nil
+}
+
+// HasSchema queries whether the database dbName has a table named "kubernetes_schema".
+// For PostgreSQL, only the current schema of the database is considered.
+func (db *Database) HasSchema(ctx context.Context, dbName string) (bool, error) {
+	var query string
+	switch db.DriverName() {
+	case PostgreSQL:
+		query = "SELECT 1 FROM information_schema.tables" +
+			" WHERE table_catalog=? AND table_schema=current_schema() AND table_name='kubernetes_schema'"
+	default:
+		query = "SELECT 1 FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA=? AND TABLE_NAME='kubernetes_schema'"
+	}
+
+	rows, err := db.QueryContext(ctx, db.Rebind(query), dbName)
+	if err != nil {
+		return false, CantPerformQuery(err, query)
+	}
+
+	defer func() { _ = rows.Close() }()
+
+	return rows.Next(), rows.Err()
+}
+
+// SchemaVersion returns the version of the most recently applied schema.
+// Returns ErrSchemaPartiallyUpgraded if the upgrade to that version has not completed.
+func (db *Database) SchemaVersion(ctx context.Context) (string, error) {
+	var v string
+	var success types.Bool
+
+	err := retry.WithBackoff(
+		ctx,
+		func(ctx context.Context) (err error) {
+			query := "SELECT version, success FROM kubernetes_schema ORDER BY id DESC LIMIT 1"
+			err = db.QueryRowxContext(ctx, query).Scan(&v, &success)
+			if err != nil {
+				err = CantPerformQuery(err, query)
+			}
+			return
+		},
+		retry.Retryable,
+		backoff.NewExponentialWithJitter(128*time.Millisecond, 1*time.Minute),
+		retry.Settings{})
+	if err != nil {
+		return "", err
+	}
+
+	if success.Valid && !success.Bool {
+		return v, errors.Wrapf(ErrSchemaPartiallyUpgraded, "upgrade to schema version %s has not completed", v)
+	}
+
+	return v, nil
+}
+
+// ImportSchema executes all statements of the given DDL.
+func (db *Database) ImportSchema(ctx context.Context, ddl string) error {
+	for _, stmt := range SplitStatements(ddl) {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return CantPerformQuery(err, stmt)
+		}
+	}
+
+	return nil
+}
+
+// DropSchema drops all views and tables of the database dbName.
+// For PostgreSQL, all views, tables and enum types of the current schema are dropped.
+// Views are dropped first, as tables cannot be dropped while views depend on them.
+func (db *Database) DropSchema(ctx context.Context, dbName string) error {
+	var views []string
+	query := "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.VIEWS WHERE TABLE_SCHEMA=?"
+	if db.DriverName() == PostgreSQL {
+		query = "SELECT table_name FROM information_schema.views WHERE table_catalog=? AND table_schema=current_schema()"
+	}
+
+	if err := db.SelectContext(ctx, &views, db.Rebind(query), dbName); err != nil {
+		return errors.Wrap(err, "cannot fetch views")
+	}
+
+	for _, view := range views {
+		stmt := fmt.Sprintf(`DROP VIEW %s`, db.QuoteIdentifier(view))
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return CantPerformQuery(err, stmt)
+		}
+	}
+
+	var tables []string
+	query = "SELECT TABLE_NAME FROM INFORMATION_SCHEMA.TABLES WHERE TABLE_SCHEMA=? AND TABLE_TYPE='BASE TABLE'"
+	if db.DriverName() == PostgreSQL {
+		query = "SELECT table_name FROM information_schema.tables" +
+			" WHERE table_catalog=? AND table_schema=current_schema() AND table_type='BASE TABLE'"
+	}
+
+	if err := db.SelectContext(ctx, &tables, db.Rebind(query), dbName); err != nil {
+		return errors.Wrap(err, "cannot fetch tables")
+	}
+
+	for _, table := range tables {
+		stmt := fmt.Sprintf(`DROP TABLE %s`, db.QuoteIdentifier(table))
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return CantPerformQuery(err, stmt)
+		}
+	}
+
+	if db.DriverName() == PostgreSQL {
+		var enums []string
+		query := "SELECT t.typname FROM pg_type t JOIN pg_namespace n ON n.oid = t.typnamespace" +
+			" WHERE n.nspname = current_schema() AND t.typtype = 'e'"
+		if err := db.SelectContext(ctx, &enums, query); err != nil {
+			return errors.Wrap(err, "cannot fetch types")
+		}
+
+		for _, enum := range enums {
+			stmt := fmt.Sprintf(`DROP TYPE %s`, db.QuoteIdentifier(enum))
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				return CantPerformQuery(err, stmt)
+			}
+		}
+	}
+
+	return nil
+}
+
+// MigrateSchema upgrades the schema from version current to version target by applying migrations step by step.
+// Each step runs in its own transaction, which also records the new schema version in kubernetes_schema.
+// Note that MySQL, unlike PostgreSQL, implicitly commits DDL statements, so a failed step may leave
+// the schema partially upgraded. Such a step remains recorded as unsuccessful, see ErrSchemaPartiallyUpgraded.
+// Returns ErrSchemaTooNew if current is newer than target and
+// ErrNoUpgradePath if there are no upgrade scripts that lead from current to target.
+func (db *Database) MigrateSchema(ctx context.Context, migrations *Migrations, current, target string) error {
+	to, err := version.ParseSemantic(target)
+	if err != nil {
+		return errors.Wrapf(err, "invalid target schema version %q", target)
+	}
+
+	from, err := version.ParseSemantic(current)
+	if err != nil {
+		return errors.Wrapf(ErrNoUpgradePath, "unrecognized schema version %q", current)
+	}
+
+	if from.EqualTo(to) {
+		return nil
+	}
+
+	if from.GreaterThan(to) {
+		return errors.Wrapf(
+			ErrSchemaTooNew, "schema version %s is newer than the supported version %s", current, target)
+	}
+
+	known := from.EqualTo(migrations.Baseline)
+	var pending []Migration
+	for _, m := range migrations.Steps {
+		if m.Version.EqualTo(from) {
+			known = true
+		}
+
+		if m.Version.GreaterThan(from) && !m.Version.GreaterThan(to) {
+			pending = append(pending, m)
+		}
+	}
+
+	if !known || len(pending) == 0 || !pending[len(pending)-1].Version.EqualTo(to) {
+		return errors.Wrapf(ErrNoUpgradePath, "cannot upgrade schema from version %s to %s", current, target)
+	}
+
+	for _, m := range pending {
+		db.log.Info("Upgrading schema", "from", from.String(), "to", m.Version.String())
+
+		if err := db.migrate(ctx, m, from); err != nil {
+			return errors.Wrapf(err, "cannot upgrade schema from version %s to %s", from, m.Version)
+		}
+
+		from = m.Version
+	}
+
+	return nil
+}
+
+// migrate applies the given migration and records its version in a single transaction.
+// The version is recorded as unsuccessful before the statements are executed and marked as successful afterwards.
+// If MySQL implicitly commits a DDL statement of a failing migration, the unsuccessful record remains.
+func (db *Database) migrate(ctx context.Context, m Migration, from *version.Version) error {
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "cannot start transaction")
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	stmt := db.Rebind("INSERT INTO kubernetes_schema (version, timestamp, success, reason) VALUES (?, ?, ?, ?)")
+	if _, err := tx.ExecContext(
+		ctx,
+		stmt,
+		m.Version.String(),
+		types.UnixMilli(time.Now()),
+		types.Bool{Bool: false, Valid: true},
+		fmt.Sprintf("Incomplete upgrade from %s", from),
+	); err != nil {
+		return CantPerformQuery(err, stmt)
+	}
+
+	for _, stmt := range SplitStatements(m.Script) {
+		if _, err := tx.ExecContext(ctx, stmt); err != nil {
+			return CantPerformQuery(err, stmt)
+		}
+	}
+
+	stmt = db.Rebind("UPDATE kubernetes_schema SET success = ?, reason = ? WHERE version = ?")
+	if _, err := tx.ExecContext(
+		ctx,
+		stmt,
+		types.Bool{Bool: true, Valid: true},
+		fmt.Sprintf("Upgrade from %s", from),
+		m.Version.String(),
+	); err != nil {
+		return CantPerformQuery(err, stmt)
+	}
+
+	return errors.Wrap(tx.Commit(), "cannot commit transaction")
+}
+
+// SplitStatements splits the given SQL script into its individual, non-empty statements.
+func SplitStatements(script string) []string {
+	var stmts []string
+	for _, stmt := range strings.Split(script, ";") {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+
+	return stmts
+}
@@ -0,0 +1,307 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"io/fs"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	tests := []struct {
+		name     string
+		files    fstest.MapFS
+		baseline string
+		versions []string
+		error    bool
+	}{
+		{
+			name:     "empty",
+			files:    fstest.MapFS{"upgrades": &fstest.MapFile{Mode: fs.ModeDir}},
+			baseline: "0.4.0",
+		},
+		{
+			name: "sorted",
+			files: fstest.MapFS{
+				"upgrades/0.10.0.sql": {Data: []byte("SELECT 3")},
+				"upgrades/0.5.0.sql":  {Data: []byte("SELECT 1")},
+				"upgrades/0.6.0.sql":  {Data: []byte("SELECT 2")},
+			},
+			baseline: "0.4.0",
+			versions: []string{"0.5.0", "0.6.0", "0.10.0"},
+		},
+		{
+			name: "ignores-other-files",
+			files: fstest.MapFS{
+				"upgrades/0.5.0.sql":        {Data: []byte("SELECT 1")},
+				"upgrades/README.md":        {Data: []byte("# Upgrades")},
+				"upgrades/0.6.0/schema.sql": {Data: []byte("SELECT 2")},
+			},
+			baseline: "0.4.0",
+			versions: []string{"0.5.0"},
+		},
+		{
+			name:     "invalid-name",
+			files:    fstest.MapFS{"upgrades/latest.sql": {Data: []byte("SELECT 1")}},
+			baseline: "0.4.0",
+			error:    true,
+		},
+		{
+			name:     "not-after-baseline",
+			files:    fstest.MapFS{"upgrades/0.4.0.sql": {Data: []byte("SELECT 1")}},
+			baseline: "0.4.0",
+			error:    true,
+		},
+		{
+			name:     "invalid-baseline",
+			files:    fstest.MapFS{"upgrades/0.5.0.sql": {Data: []byte("SELECT 1")}},
+			baseline: "latest",
+			error:    true,
+		},
+		{
+			name:     "missing-dir",
+			files:    fstest.MapFS{},
+			baseline: "0.4.0",
+			error:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := LoadMigrations(tt.files, "upgrades", tt.baseline)
+			if tt.error {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !migrations.Baseline.EqualTo(version.MustParseSemantic(tt.baseline)) {
+				t.Errorf("baseline = %s, want %s", migrations.Baseline, tt.baseline)
+			}
+
+			if len(migrations.Steps) != len(tt.versions) {
+				t.Fatalf("got %d steps, want %d", len(migrations.Steps), len(tt.versions))
+			}
+
+			for i, v := range tt.versions {
+				if got := migrations.Steps[i].Version.String(); got != v {
+					t.Errorf("steps[%d] = %s, want %s", i, got, v)
+				}
+			}
+		})
+	}
+}
+
+func TestMigrateSchema(t *testing.T) {
+	migrations := &Migrations{
+		Baseline: version.MustParseSemantic("0.4.0"),
+		Steps: []Migration{
+			{Version: version.MustParseSemantic("0.5.0")},
+			{Version: version.MustParseSemantic("0.6.0")},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		current string
+		target  string
+		error   error
+	}{
+		{name: "up-to-date", current: "0.6.0", target: "0.6.0"},
+		{name: "too-new", current: "0.7.0", target: "0.6.0", error: ErrSchemaTooNew},
+		{name: "unrecognized", current: "latest", target: "0.6.0", error: ErrNoUpgradePath},
+		{name: "before-baseline", current: "0.3.0", target: "0.6.0", error: ErrNoUpgradePath},
+		{name: "unknown-version", current: "0.5.1", target: "0.6.0", error: ErrNoUpgradePath},
+		{name: "target-without-script", current: "0.5.0", target: "0.7.0", error: ErrNoUpgradePath},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// All cases return before the database is used.
+			err := (&Database{}).MigrateSchema(context.Background(), migrations, tt.current, tt.target)
+			if !errors.Is(err, tt.error) {
+				t.Errorf("MigrateSchema() = %v, want %v", err, tt.error)
+			}
+		})
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		name   string
+		script string
+		stmts  []string
+	}{
+		{name: "empty", script: ""},
+		{name: "whitespace", script: " \n;\n ; "},
+		{name: "single", script: "SELECT 1", stmts: []string{"SELECT 1"}},
+		{
+			name:   "multiple",
+			script: "CREATE TABLE a (id int);\n\nALTER TABLE a ADD COLUMN b int;\n",
+			stmts:  []string{"CREATE TABLE a (id int)", "ALTER TABLE a ADD COLUMN b int"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stmts := SplitStatements(tt.script)
+			if len(stmts) != len(tt.stmts) {
+				t.Fatalf("SplitStatements() = %q, want %q", stmts, tt.stmts)
+			}
+
+			for i := range stmts {
+				if stmts[i] != tt.stmts[i] {
+					t.Errorf("SplitStatements()[%d] = %q, want %q", i, stmts[i], tt.stmts[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDropSchema(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver string
+		views  []string
+		tables []string
+		want   []string
+	}{
+		{
+			name:   "mysql",
+			driver: MySQL,
+			views:  []string{"pvc_metric_series"},
+			tables: []string{"pvc", "pvc_metric"},
+			want:   []string{"DROP VIEW `pvc_metric_series`", "DROP TABLE `pvc`", "DROP TABLE `pvc_metric`"},
+		},
+		{
+			name:   "mysql-without-views",
+			driver: MySQL,
+			tables: []string{"pvc"},
+			want:   []string{"DROP TABLE `pvc`"},
+		},
+		{
+			name:   "pgsql",
+			driver: PostgreSQL,
+			views:  []string{"pvc_metric_series"},
+			tables: []string{"pvc", "pvc_metric"},
+			want:   []string{`DROP VIEW "pvc_metric_series"`, `DROP TABLE "pvc"`, `DROP TABLE "pvc_metric"`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn := &schemaConn{views: slices.Clone(tt.views), tables: slices.Clone(tt.tables)}
+			db := sqlx.NewDb(sql.OpenDB(schemaConnector{conn: conn}), tt.driver)
+			defer func() { _ = db.Close() }()
+
+			if err := (&Database{DB: db, Quoter: NewQuoter(db)}).DropSchema(context.Background(), "kubernetes"); err != nil {
+				t.Fatalf("DropSchema() = %v", err)
+			}
+
+			if !slices.Equal(conn.executed, tt.want) {
+				t.Errorf("DropSchema() executed %q, want %q", conn.executed, tt.want)
+			}
+		})
+	}
+}
+
+// schemaConnector is a driver.Connector that always returns the same schemaConn.
+type schemaConnector struct {
+	conn *schemaConn
+}
+
+func (c schemaConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.conn, nil
+}
+
+func (c schemaConnector) Driver() driver.Driver {
+	return nil
+}
+
+// schemaConn is a driver.Conn that emulates the information schema of a database with the given views and tables.
+// Like MySQL, it lists views as tables unless only base tables are selected, and like both databases,
+// it refuses to drop views using DROP TABLE and to drop tables while views exist.
+type schemaConn struct {
+	views    []string
+	tables   []string
+	executed []string
+}
+
+func (c *schemaConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *schemaConn) Close() error {
+	return nil
+}
+
+func (c *schemaConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not supported")
+}
+
+func (c *schemaConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	var names []string
+	switch query = strings.ToLower(query); {
+	case strings.Contains(query, "information_schema.views"):
+		names = slices.Clone(c.views)
+	case strings.Contains(query, "information_schema.tables"):
+		names = slices.Clone(c.tables)
+		if !strings.Contains(query, "'base table'") {
+			names = append(names, c.views...)
+		}
+	}
+
+	return &nameRows{names: names}, nil
+}
+
+func (c *schemaConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if strings.HasPrefix(query, "DROP TABLE") && len(c.views) > 0 {
+		return nil, errors.Errorf("cannot drop table while views exist: %s", query)
+	}
+
+	if strings.HasPrefix(query, "DROP VIEW") {
+		c.views = c.views[1:]
+	}
+
+	c.executed = append(c.executed, query)
+
+	return driver.RowsAffected(0), nil
+}
+
+// nameRows are driver.Rows with a single column of names.
+type nameRows struct {
+	names []string
+}
+
+func (r *nameRows) Columns() []string {
+	return []string{"table_name"}
+}
+
+func (r *nameRows) Close() error {
+	return nil
+}
+
+func (r *nameRows) Next(dest []driver.Value) error {
+	if len(r.names) == 0 {
+		return io.EOF
+	}
+
+	dest[0], r.names = r.names[0], r.names[1:]
+
+	return nil
+}
@@ -0,0 +1,61 @@
+package downtime
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Config lists the downtimes defined in the configuration file.
+type Config []Definition
+
+// Definition defines a downtime. It covers the whole cluster unless restricted to
+// a namespace, a label selector or a single resource, which are combined if multiple are set.
+type Definition struct {
+	Namespace string `yaml:"namespace"`
+	Selector  string `yaml:"selector"`
+	Uuid      string `yaml:"uuid"`
+	// Start and End are times in RFC 3339 format.
+	Start   string `yaml:"start"`
+	End     string `yaml:"end"`
+	Comment string `yaml:"comment"`
+
+	start time.Time
+	end   time.Time
+}
+
+// Validate checks constraints in the supplied downtimes configuration and returns an error if they are violated.
+func (c Config) Validate() error {
+	for i := range c {
+		d := &c[i]
+
+		if d.Selector != "" {
+			if _, err := labels.Parse(d.Selector); err != nil {
+				return errors.Wrapf(err, "invalid 'selector' of downtime %d", i)
+			}
+		}
+
+		if d.Uuid != "" {
+			if _, err := uuid.Parse(d.Uuid); err != nil {
+				return errors.Wrapf(err, "invalid 'uuid' of downtime %d", i)
+			}
+		}
+
+		var err error
+		if d.start, err = time.Parse(time.RFC3339, d.Start); err != nil {
+			return errors.Wrapf(err, "invalid 'start' of downtime %d", i)
+		}
+
+		if d.end, err = time.Parse(time.RFC3339, d.End); err != nil {
+			return errors.Wrapf(err, "invalid 'end' of downtime %d", i)
+		}
+
+		if !d.end.After(d.start) {
+			return errors.Errorf("'end' of downtime %d must be after its 'start'", i)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package downtime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/icinga/icinga-go-library/database"
+	"github.com/icinga/icinga-go-library/types"
+	schemav1 "github.com/icinga/icinga-kubernetes/pkg/schema/v1"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// Sync replaces the locked downtimes of the given cluster in the database with the configured ones.
+// Downtimes created otherwise, e.g. via Icinga for Kubernetes Web, are left alone.
+func Sync(ctx context.Context, db *database.DB, c Config, clusterUuid types.UUID) error {
+	_true := types.Bool{Bool: true, Valid: true}
+
+	toDb := make([]schemav1.Downtime, 0, len(c))
+	for i, d := range c {
+		downtime := schemav1.Downtime{
+			Uuid:          schemav1.NewUUID(clusterUuid, fmt.Sprintf("downtime-%d", i)),
+			ClusterUuid:   clusterUuid,
+			Namespace:     schemav1.NewNullableString(d.Namespace),
+			LabelSelector: schemav1.NewNullableString(d.Selector),
+			StartTime:     types.UnixMilli(d.start),
+			EndTime:       types.UnixMilli(d.end),
+			Comment:       d.Comment,
+			Locked:        _true,
+		}
+		if d.Uuid != "" {
+			id := uuid.MustParse(d.Uuid)
+			downtime.ResourceUuid = id[:]
+		}
+
+		toDb = append(toDb, downtime)
+	}
+
+	err := db.ExecTx(ctx, func(ctx context.Context, tx *sqlx.Tx) error {
+		if _, err := tx.ExecContext(
+			ctx,
+			tx.Rebind(fmt.Sprintf(
+				`DELETE FROM "%s" WHERE "cluster_uuid" = ? AND "locked" = ?`,
+				database.TableName(&schemav1.Downtime{}),
+			)),
+			clusterUuid,
+			_true,
+		); err != nil {
+			return errors.Wrap(err, "cannot delete downtimes")
+		}
+
+		if len(toDb) > 0 {
+			stmt, _ := db.BuildInsertStmt(schemav1.Downtime{})
+			if _, err := tx.NamedExecContext(ctx, stmt, toDb); err != nil {
+				return errors.Wrap(err, "cannot insert downtimes")
+			}
+		}
+
+		return nil
+	})
+
+	return errors.Wrap(err, "transaction failed")
+}
+
+// Downtimes holds the current and upcoming downtimes of a cluster loaded from the database,
+// including those not defined in the configuration file.
+type Downtimes struct {
+	db          *database.DB
+	clusterUuid types.UUID
+
+	mu        sync.RWMutex
+	downtimes []downtime
+}
+
+type downtime struct {
+	schemav1.Downtime
+	selector labels.Selector
+}
+
+// NewDowntimes creates new Downtimes for the given cluster. Downtimes are only known after Load.
+func NewDowntimes(db *database.DB, clusterUuid types.UUID) *Downtimes {
+	return &Downtimes{db: db, clusterUuid: clusterUuid}
+}
+
+// Load (re)loads the downtimes that have not ended yet from the database.
+func (d *Downtimes) Load(ctx context.Context) error {
+	var rows []schemav1.Downtime
+	if err := d.db.SelectContext(
+		ctx,
+		&rows,
+		d.db.Rebind(
+			d.db.BuildSelectStmt(schemav1.Downtime{}, schemav1.Downtime{})+` WHERE cluster_uuid = ? AND end_time > ?`,
+		),
+		d.clusterUuid,
+		types.UnixMilli(time.Now()),
+	); err != nil {
+		return errors.Wrap(err, "cannot fetch downtimes")
+	}
+
+	downtimes := make([]downtime, 0, len(rows))
+	for _, row := range rows {
+		dt := downtime{Downtime: row, selector: labels.Everything()}
+		if row.LabelSelector.Valid {
+			selector, err := labels.Parse(row.LabelSelector.String)
+			if err != nil {
+				klog.Errorf("Ignoring downtime %s with invalid label selector %q: %v", row.Uuid, row.LabelSelector.String, err)
+
+				continue
+			}
+			dt.selector = selector
+		}
+
+		downtimes = append(downtimes, dt)
+	}
+
+	d.mu.Lock()
+	d.downtimes = downtimes
+	d.mu.Unlock()
+
+	return nil
+}
+
+// Active returns whether the resource of the given UUID, namespace and labels is currently covered by a downtime,
+// and if so, when the last of the covering downtimes ends.
+func (d *Downtimes) Active(id types.UUID, namespace string, resourceLabels map[string]string) (time.Time, bool) {
+	now := time.Now()
+	var end time.Time
+
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, dt := range d.downtimes {
+		if now.Before(dt.StartTime.Time()) || !now.Before(dt.EndTime.Time()) {
+			continue
+		}
+
+		if dt.Namespace.Valid && dt.Namespace.String != namespace {
+			continue
+		}
+
+		if len(dt.ResourceUuid) > 0 && string(dt.ResourceUuid) != string(id.UUID[:]) {
+			continue
+		}
+
+		if !dt.selector.Matches(labels.Set(resourceLabels)) {
+			continue
+		}
+
+		if dt.EndTime.Time().After(end) {
+			end = dt.EndTime.Time()
+		}
+	}
+
+	return end, !end.IsZero()
+}
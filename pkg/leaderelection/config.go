@@ -0,0 +1,48 @@
+package leaderelection
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	kleaderelection "k8s.io/client-go/tools/leaderelection"
+)
+
+// Config defines leader election configuration.
+type Config struct {
+	// If Enabled is false, the instance always acts as the leader.
+	Enabled bool `yaml:"enabled" env:"ENABLED"`
+	// LeaseName is the name of the Lease object all instances of the same cluster compete for.
+	LeaseName string `yaml:"lease_name" env:"LEASE_NAME" default:"icinga-kubernetes"`
+	// If LeaseNamespace is the empty string, the namespace of the service account is used
+	// when running inside a Kubernetes cluster and "default" otherwise.
+	LeaseNamespace string        `yaml:"lease_namespace" env:"LEASE_NAMESPACE"`
+	LeaseDuration  time.Duration `yaml:"lease_duration" env:"LEASE_DURATION" default:"15s"`
+	RenewDeadline  time.Duration `yaml:"renew_deadline" env:"RENEW_DEADLINE" default:"10s"`
+	RetryPeriod    time.Duration `yaml:"retry_period" env:"RETRY_PERIOD" default:"2s"`
+}
+
+// Validate checks constraints in the supplied leader election configuration and returns an error if they are violated.
+func (c *Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+
+	if c.LeaseName == "" {
+		return errors.New("'lease_name' must not be empty")
+	}
+
+	if c.RetryPeriod <= 0 {
+		return errors.New("'retry_period' must be positive")
+	}
+
+	if c.RenewDeadline <= time.Duration(kleaderelection.JitterFactor*float64(c.RetryPeriod)) {
+		return errors.Errorf(
+			"'renew_deadline' must be greater than %v times 'retry_period'", kleaderelection.JitterFactor)
+	}
+
+	if c.LeaseDuration <= c.RenewDeadline {
+		return errors.New("'lease_duration' must be greater than 'renew_deadline'")
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package leaderelection
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kleaderelection "k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+// serviceAccountNamespaceFile contains the namespace of the pod's service account if running inside a cluster.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Elector campaigns for the leadership of all instances that synchronize the same cluster
+// using a Lease object of the coordination.k8s.io API group.
+type Elector struct {
+	elector *kleaderelection.LeaderElector
+	log     logr.Logger
+	leading chan struct{}
+	stopped chan struct{}
+}
+
+// NewElector creates a new Elector for the given configuration.
+// identity must uniquely identify the instance among all instances competing for the lease.
+func NewElector(clientset kubernetes.Interface, c *Config, identity string, log logr.Logger) (*Elector, error) {
+	namespace := c.LeaseNamespace
+	if namespace == "" {
+		namespace = "default"
+
+		if ns, err := os.ReadFile(serviceAccountNamespaceFile); err == nil {
+			if ns := strings.TrimSpace(string(ns)); ns != "" {
+				namespace = ns
+			}
+		}
+	}
+
+	e := &Elector{
+		log:     log,
+		leading: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	elector, err := kleaderelection.NewLeaderElector(kleaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Name: c.LeaseName, Namespace: namespace},
+			Client:     clientset.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		LeaseDuration:   c.LeaseDuration,
+		RenewDeadline:   c.RenewDeadline,
+		RetryPeriod:     c.RetryPeriod,
+		ReleaseOnCancel: true,
+		Name:            c.LeaseName,
+		Callbacks: kleaderelection.LeaderCallbacks{
+			OnStartedLeading: func(context.Context) {
+				log.Info("Started leading", "lease", namespace+"/"+c.LeaseName)
+
+				close(e.leading)
+			},
+			OnStoppedLeading: func() {
+				close(e.stopped)
+			},
+			OnNewLeader: func(leader string) {
+				if leader != identity {
+					log.Info("New leader elected", "leader", leader)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "cannot create leader elector")
+	}
+
+	e.elector = elector
+
+	return e, nil
+}
+
+// Run campaigns for the leadership until ctx is canceled or the leadership is lost.
+// Once Run returns, the Elector cannot be run again.
+func (e *Elector) Run(ctx context.Context) {
+	e.log.Info("Campaigning for leadership")
+
+	e.elector.Run(ctx)
+}
+
+// IsLeader returns whether the instance is currently the leader.
+func (e *Elector) IsLeader() bool {
+	return e.elector.IsLeader()
+}
+
+// Leading returns a channel that is closed once the instance has become the leader.
+func (e *Elector) Leading() <-chan struct{} {
+	return e.leading
+}
+
+// Stopped returns a channel that is closed once Run has returned,
+// i.e. the leadership has been lost or the campaign has been canceled.
+func (e *Elector) Stopped() <-chan struct{} {
+	return e.stopped
+}
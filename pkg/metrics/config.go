@@ -1,7 +1,10 @@
 package metrics
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
+	"github.com/prometheus/common/model"
 )
 
 // PrometheusConfig defines Prometheus configuration.
@@ -10,6 +13,8 @@ type PrometheusConfig struct {
 	Insecure string `yaml:"insecure" env:"INSECURE"`
 	Username string `yaml:"username" env:"USERNAME"`
 	Password string `yaml:"password" env:"PASSWORD"`
+	// Queries are merged over the built-in queries, see PromQueries.
+	Queries []PromQuery `yaml:"queries"`
 }
 
 // Validate checks constraints in the supplied Prometheus configuration and returns an error if they are violated.
@@ -24,5 +29,78 @@ func (c *PrometheusConfig) Validate() error {
 		}
 	}
 
+	seen := make(map[string]struct{}, len(c.Queries))
+	for i, q := range c.Queries {
+		switch q.Entity {
+		case EntityCluster, EntityNode, EntityPod, EntityContainer:
+		default:
+			return errors.Errorf("'entity' of query %d must be one of %q, %q, %q or %q",
+				i, EntityCluster, EntityNode, EntityPod, EntityContainer)
+		}
+
+		if q.Category == "" {
+			return errors.Errorf("'category' of query %d required", i)
+		}
+
+		key := q.Entity + "/" + q.Category
+		if _, ok := seen[key]; ok {
+			return errors.Errorf("query %d duplicates category %q of entity %q", i, q.Category, q.Entity)
+		}
+		seen[key] = struct{}{}
+
+		if q.Disabled {
+			continue
+		}
+
+		if q.Query == "" {
+			return errors.Errorf("'query' of query %d required unless disabled", i)
+		}
+
+		if q.NameLabel != "" && !q.NameLabel.IsValid() {
+			return errors.Errorf("'name_label' of query %d is not a valid label name", i)
+		}
+
+		for option, label := range map[string]model.LabelName{
+			"node_label": q.NodeLabel, "namespace_label": q.NamespaceLabel, "pod_label": q.PodLabel,
+			"container_label": q.ContainerLabel,
+		} {
+			if label != "" && !label.IsValid() {
+				return errors.Errorf("'%s' of query %d is not a valid label name", option, i)
+			}
+		}
+
+		if q.Interval < 0 {
+			return errors.Errorf("'interval' of query %d must not be negative", i)
+		}
+	}
+
+	return nil
+}
+
+// KubeletConfig defines the collection of storage usage from the Summary API of the kubelets.
+type KubeletConfig struct {
+	// Disabled disables the collection, e.g. if access to the nodes/proxy resource is not granted.
+	Disabled bool          `yaml:"disabled" env:"DISABLED"`
+	Interval time.Duration `yaml:"interval" env:"INTERVAL" default:"1m"`
+	// Warning and Critical are the percentages of their capacity from which PVCs and containers are
+	// considered warning or critical, respectively. The capacity of containers is their ephemeral storage limit.
+	Warning  float64 `yaml:"warning" env:"WARNING" default:"80"`
+	Critical float64 `yaml:"critical" env:"CRITICAL" default:"90"`
+}
+
+// Validate checks constraints in the supplied kubelet configuration and returns an error if they are violated.
+func (c *KubeletConfig) Validate() error {
+	if c.Interval <= 0 {
+		return errors.New("'interval' must be positive")
+	}
+
+	if c.Warning <= 0 || c.Warning > 100 || c.Critical <= 0 || c.Critical > 100 {
+		return errors.New("'warning' and 'critical' must be percentages greater than 0")
+	}
+
+	if c.Warning > c.Critical {
+		return errors.New("'warning' must not be greater than 'critical'")
+	}
+
 	return nil
 }
@@ -0,0 +1,101 @@
+package metrics
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPrometheusConfigValidate(t *testing.T) {
+	query := PromQuery{Entity: EntityNode, Category: "memory.usage", Query: "up"}
+
+	tests := []struct {
+		name    string
+		config  PrometheusConfig
+		wantErr bool
+	}{
+		{name: "empty", config: PrometheusConfig{}},
+		{name: "url", config: PrometheusConfig{Url: "http://localhost:9090"}},
+		{name: "credentials", config: PrometheusConfig{Url: "http://localhost:9090", Username: "u", Password: "p"}},
+		{name: "username-only", config: PrometheusConfig{Url: "http://localhost:9090", Username: "u"}, wantErr: true},
+		{name: "password-only", config: PrometheusConfig{Url: "http://localhost:9090", Password: "p"}, wantErr: true},
+		{name: "insecure", config: PrometheusConfig{Url: "http://localhost:9090", Insecure: "true"}},
+		{name: "insecure-invalid", config: PrometheusConfig{Url: "http://localhost:9090", Insecure: "yes"}, wantErr: true},
+		{name: "query", config: PrometheusConfig{Queries: []PromQuery{query}}},
+		{
+			name: "query-full",
+			config: PrometheusConfig{Queries: []PromQuery{{
+				Entity: EntityPod, Category: "restarts", Query: "up", NameLabel: "container", Interval: time.Minute,
+				NamespaceLabel: "exported_namespace", PodLabel: "exported_pod",
+			}}},
+		},
+		{
+			name:    "entity-invalid",
+			config:  PrometheusConfig{Queries: []PromQuery{{Entity: "service", Category: "cpu.usage", Query: "up"}}},
+			wantErr: true,
+		},
+		{
+			name:    "category-missing",
+			config:  PrometheusConfig{Queries: []PromQuery{{Entity: EntityNode, Query: "up"}}},
+			wantErr: true,
+		},
+		{name: "duplicate", config: PrometheusConfig{Queries: []PromQuery{query, query}}, wantErr: true},
+		{
+			name: "same-category-other-entity",
+			config: PrometheusConfig{Queries: []PromQuery{
+				query, {Entity: EntityPod, Category: query.Category, Query: "up"},
+			}},
+		},
+		{
+			name:    "query-missing",
+			config:  PrometheusConfig{Queries: []PromQuery{{Entity: EntityNode, Category: "memory.usage"}}},
+			wantErr: true,
+		},
+		{
+			name:   "disabled",
+			config: PrometheusConfig{Queries: []PromQuery{{Entity: EntityNode, Category: "memory.usage", Disabled: true}}},
+		},
+		{
+			name: "name-label-invalid",
+			config: PrometheusConfig{Queries: []PromQuery{{
+				Entity: EntityNode, Category: "memory.usage", Query: "up", NameLabel: "\xff",
+			}}},
+			wantErr: true,
+		},
+		{
+			name: "node-label-invalid",
+			config: PrometheusConfig{Queries: []PromQuery{{
+				Entity: EntityNode, Category: "memory.usage", Query: "up", NodeLabel: "node\xff",
+			}}},
+			wantErr: true,
+		},
+		{
+			name: "pod-label-invalid",
+			config: PrometheusConfig{Queries: []PromQuery{{
+				Entity: EntityPod, Category: "memory.usage", Query: "up", PodLabel: "\xffpod",
+			}}},
+			wantErr: true,
+		},
+		{
+			name: "container-label-invalid",
+			config: PrometheusConfig{Queries: []PromQuery{{
+				Entity: EntityContainer, Category: "memory.usage", Query: "up", ContainerLabel: "\xff",
+			}}},
+			wantErr: true,
+		},
+		{
+			name: "interval-negative",
+			config: PrometheusConfig{Queries: []PromQuery{{
+				Entity: EntityNode, Category: "memory.usage", Query: "up", Interval: -time.Minute,
+			}}},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %t", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,366 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/icinga/icinga-go-library/database"
+	"github.com/icinga/icinga-go-library/logging"
+	"github.com/icinga/icinga-go-library/periodic"
+	"github.com/icinga/icinga-go-library/types"
+	schemav1 "github.com/icinga/icinga-kubernetes/pkg/schema/v1"
+	syncv1 "github.com/icinga/icinga-kubernetes/pkg/sync/v1"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	kcorev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	kcache "k8s.io/client-go/tools/cache"
+)
+
+// kubeletConcurrency limits the number of kubelets queried at the same time.
+const kubeletConcurrency = 8
+
+// fsStats are the filesystem stats of the kubelet Summary API.
+// Objects of the API are decoded into own types, so that k8s.io/kubelet is not required.
+type fsStats struct {
+	CapacityBytes *uint64 `json:"capacityBytes"`
+	UsedBytes     *uint64 `json:"usedBytes"`
+}
+
+// summary is the response of the kubelet Summary API, i.e. /stats/summary.
+type summary struct {
+	Pods []struct {
+		PodRef struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		} `json:"podRef"`
+		Containers []struct {
+			Name   string   `json:"name"`
+			Rootfs *fsStats `json:"rootfs"`
+			Logs   *fsStats `json:"logs"`
+		} `json:"containers"`
+		Volumes []struct {
+			fsStats
+			PvcRef *struct {
+				Name      string `json:"name"`
+				Namespace string `json:"namespace"`
+			} `json:"pvcRef"`
+		} `json:"volume"`
+		EphemeralStorage *fsStats `json:"ephemeral-storage"`
+	} `json:"pods"`
+}
+
+// storageTarget is a PVC or pod whose Icinga state depends on a storage usage,
+// and which is requeued using requeuer under key if that state changes.
+type storageTarget struct {
+	requeuer *syncv1.Requeuer
+	key      string
+}
+
+// KubeletSync synchronizes the storage usage of PVCs, pods and containers from the Summary API
+// of the kubelets, which is queried through the node proxy of the API server, to the database.
+// In addition, the usages are made available to the Icinga states of PVCs and pods, see schemav1.SetStorageUsage.
+type KubeletSync struct {
+	clientset kubernetes.Interface
+	db        *database.DB
+	logger    *logging.Logger
+	interval  time.Duration
+	pvcs      *syncv1.Requeuer
+	pods      *syncv1.Requeuer
+
+	mu sync.Mutex
+	// known holds the storage targets whose usage is known per node name,
+	// so that the usage of targets no longer reported is forgotten.
+	known map[string]map[types.UUID]storageTarget
+}
+
+// NewKubeletSync creates a new KubeletSync. PVCs and pods whose Icinga state changes due to their storage usage
+// are requeued using the given requeuers, which may be nil if the respective resources are not synchronized.
+func NewKubeletSync(
+	clientset kubernetes.Interface, db *database.DB, logger *logging.Logger, interval time.Duration,
+	pvcs, pods *syncv1.Requeuer,
+) *KubeletSync {
+	return &KubeletSync{
+		clientset: clientset,
+		db:        db,
+		logger:    logger,
+		interval:  interval,
+		pvcs:      pvcs,
+		pods:      pods,
+		known:     make(map[string]map[types.UUID]storageTarget),
+	}
+}
+
+// Run queries the kubelets of all nodes known to the given node informer in every interval.
+// Only PVCs and pods known to any of the given informers are considered.
+func (ks *KubeletSync) Run(
+	ctx context.Context, nodes kcache.SharedIndexInformer, pvcs, pods []kcache.SharedIndexInformer,
+) error {
+	for _, informer := range append(append([]kcache.SharedIndexInformer{nodes}, pvcs...), pods...) {
+		if !kcache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			return errors.New("timed out waiting for caches to sync")
+		}
+	}
+
+	upsertPvcMetrics := make(chan database.Entity)
+	upsertPodMetrics := make(chan database.Entity)
+	upsertContainerMetrics := make(chan database.Entity)
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		defer periodic.Start(ctx, ks.interval, func(tick periodic.Tick) {
+			ng, nctx := errgroup.WithContext(ctx)
+			ng.SetLimit(kubeletConcurrency)
+
+			names := make(map[string]struct{})
+			for _, name := range nodes.GetStore().ListKeys() {
+				names[name] = struct{}{}
+
+				ng.Go(func() error {
+					s, err := ks.summary(nctx, name)
+					if err != nil {
+						ks.logger.Warnw("Cannot query kubelet", zap.String("node", name), zap.Error(err))
+
+						return nil
+					}
+
+					return ks.process(nctx, name, minute(tick.Time), s, pvcs, pods,
+						upsertPvcMetrics, upsertPodMetrics, upsertContainerMetrics)
+				})
+			}
+
+			if err := ng.Wait(); err != nil {
+				return
+			}
+
+			ks.mu.Lock()
+			defer ks.mu.Unlock()
+
+			for name := range ks.known {
+				if _, ok := names[name]; !ok {
+					ks.forget(name, nil)
+					delete(ks.known, name)
+				}
+			}
+		}, periodic.Immediate()).Stop()
+
+		<-ctx.Done()
+
+		return ctx.Err()
+	})
+
+	g.Go(func() error {
+		return database.NewUpsert(ks.db, database.WithStatement(
+			metricUpsertStmt(ks.db, "pvc_metric", "pvc_uuid"), 5)).Stream(ctx, upsertPvcMetrics)
+	})
+
+	g.Go(func() error {
+		return database.NewUpsert(ks.db, database.WithStatement(
+			metricUpsertStmt(ks.db, "prometheus_pod_metric", "pod_uuid"), 5)).Stream(ctx, upsertPodMetrics)
+	})
+
+	g.Go(func() error {
+		return database.NewUpsert(ks.db, database.WithStatement(
+			metricUpsertStmt(ks.db, "prometheus_container_metric", "container_uuid"), 5)).
+			Stream(ctx, upsertContainerMetrics)
+	})
+
+	return g.Wait()
+}
+
+// summary queries the Summary API of the kubelet of the given node.
+func (ks *KubeletSync) summary(ctx context.Context, node string) (*summary, error) {
+	raw, err := ks.clientset.CoreV1().RESTClient().Get().
+		Resource("nodes").Name(node).SubResource("proxy").Suffix("stats/summary").
+		Do(ctx).Raw()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot query summary of node %s", node)
+	}
+
+	var s summary
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode summary of node %s", node)
+	}
+
+	return &s, nil
+}
+
+// process streams the metrics of the given summary of the given node and updates the storage usages.
+func (ks *KubeletSync) process(
+	ctx context.Context, node string, timestamp int64, s *summary, pvcs, pods []kcache.SharedIndexInformer,
+	upsertPvcMetrics, upsertPodMetrics, upsertContainerMetrics chan<- database.Entity,
+) error {
+	send := func(ch chan<- database.Entity, entity database.Entity) error {
+		select {
+		case ch <- entity:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	targets := make(map[types.UUID]storageTarget)
+
+	for _, podStats := range s.Pods {
+		key := kcache.NewObjectName(podStats.PodRef.Namespace, podStats.PodRef.Name).String()
+
+		for _, volume := range podStats.Volumes {
+			if volume.PvcRef == nil || volume.UsedBytes == nil {
+				continue
+			}
+
+			pvcKey := kcache.NewObjectName(volume.PvcRef.Namespace, volume.PvcRef.Name).String()
+			obj := lookup(pvcs, pvcKey)
+			if obj == nil {
+				continue
+			}
+			pvcUuid := schemav1.EnsureUUID(obj.(*kcorev1.PersistentVolumeClaim).UID)
+
+			usage := schemav1.StorageUsage{Used: int64(*volume.UsedBytes)}
+			if volume.CapacityBytes != nil {
+				usage.Capacity = int64(*volume.CapacityBytes)
+			}
+
+			values := map[string]float64{
+				"storage.usage.bytes":    float64(usage.Used),
+				"storage.capacity.bytes": float64(usage.Capacity),
+			}
+			if usage.Capacity > 0 {
+				values["storage.usage"] = usage.Fraction()
+			}
+
+			for category, value := range values {
+				if err := send(upsertPvcMetrics, &schemav1.PvcMetric{
+					PvcUuid:   pvcUuid,
+					Timestamp: timestamp,
+					Category:  category,
+					Value:     value,
+				}); err != nil {
+					return err
+				}
+			}
+
+			target := storageTarget{requeuer: ks.pvcs, key: pvcKey}
+			targets[pvcUuid] = target
+			if schemav1.SetStorageUsage(pvcUuid, usage) {
+				target.requeue()
+			}
+		}
+
+		obj := lookup(pods, key)
+		if obj == nil {
+			continue
+		}
+		pod := obj.(*kcorev1.Pod)
+		podUuid := schemav1.EnsureUUID(pod.UID)
+
+		if podStats.EphemeralStorage != nil && podStats.EphemeralStorage.UsedBytes != nil {
+			if err := send(upsertPodMetrics, &schemav1.PrometheusPodMetric{
+				PodUuid:   podUuid,
+				Timestamp: timestamp,
+				Category:  "ephemeral_storage.usage.bytes",
+				Value:     float64(*podStats.EphemeralStorage.UsedBytes),
+			}); err != nil {
+				return err
+			}
+		}
+
+		limits := make(map[string]int64, len(pod.Spec.Containers))
+		for _, container := range pod.Spec.Containers {
+			limits[container.Name] = container.Resources.Limits.StorageEphemeral().Value()
+		}
+
+		for _, container := range podStats.Containers {
+			containerUuid := schemav1.NewUUID(podUuid, container.Name)
+			usage := schemav1.StorageUsage{Capacity: limits[container.Name]}
+
+			for name, fs := range map[string]*fsStats{"rootfs": container.Rootfs, "logs": container.Logs} {
+				if fs == nil || fs.UsedBytes == nil {
+					continue
+				}
+
+				usage.Used += int64(*fs.UsedBytes)
+
+				if err := send(upsertContainerMetrics, &schemav1.PrometheusContainerMetric{
+					ContainerUuid: containerUuid,
+					Timestamp:     timestamp,
+					Category:      "ephemeral_storage.usage.bytes",
+					Name:          name,
+					Value:         float64(*fs.UsedBytes),
+				}); err != nil {
+					return err
+				}
+			}
+
+			if usage.Capacity > 0 {
+				if err := send(upsertContainerMetrics, &schemav1.PrometheusContainerMetric{
+					ContainerUuid: containerUuid,
+					Timestamp:     timestamp,
+					Category:      "ephemeral_storage.usage",
+					Value:         usage.Fraction(),
+				}); err != nil {
+					return err
+				}
+			}
+
+			target := storageTarget{requeuer: ks.pods, key: key}
+			targets[containerUuid] = target
+			if schemav1.SetStorageUsage(containerUuid, usage) {
+				target.requeue()
+			}
+		}
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+
+	ks.forget(node, targets)
+	ks.known[node] = targets
+
+	return nil
+}
+
+// forget forgets the storage usage of the targets known for the given node that are not in keep,
+// unless they are known for other nodes as well, e.g. PVCs mounted on multiple nodes. ks.mu must be held.
+func (ks *KubeletSync) forget(node string, keep map[types.UUID]storageTarget) {
+	for id, target := range ks.known[node] {
+		if _, ok := keep[id]; ok {
+			continue
+		}
+
+		elsewhere := false
+		for name, targets := range ks.known {
+			if _, ok := targets[id]; ok && name != node {
+				elsewhere = true
+
+				break
+			}
+		}
+
+		if !elsewhere && schemav1.ForgetStorageUsage(id) {
+			target.requeue()
+		}
+	}
+}
+
+// requeue requeues the target, unless its resources are not synchronized.
+func (t storageTarget) requeue() {
+	if t.requeuer != nil {
+		t.requeuer.Requeue(t.key)
+	}
+}
+
+// lookup returns the object with the given key from the first of the given informers that knows it, or nil.
+func lookup(informers []kcache.SharedIndexInformer, key string) interface{} {
+	for _, informer := range informers {
+		if obj, exists, err := informer.GetStore().GetByKey(key); err == nil && exists {
+			return obj
+		}
+	}
+
+	return nil
+}
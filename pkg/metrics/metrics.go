@@ -2,14 +2,15 @@ package metrics
 
 import (
 	"context"
-	"fmt"
 	"github.com/icinga/icinga-go-library/backoff"
 	"github.com/icinga/icinga-go-library/database"
 	"github.com/icinga/icinga-go-library/logging"
 	"github.com/icinga/icinga-go-library/periodic"
 	"github.com/icinga/icinga-go-library/retry"
 	"github.com/icinga/icinga-go-library/types"
+	"github.com/icinga/icinga-kubernetes/pkg/cluster"
 	schemav1 "github.com/icinga/icinga-kubernetes/pkg/schema/v1"
+	"github.com/icinga/icinga-kubernetes/pkg/telemetry"
 	"github.com/pkg/errors"
 	v1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	"github.com/prometheus/common/model"
@@ -18,255 +19,321 @@ import (
 	kcorev1 "k8s.io/api/core/v1"
 	kcache "k8s.io/client-go/tools/cache"
 	"net"
+	"slices"
 	"strings"
 	"sync"
 	"time"
 )
 
+// Entities whose metrics are synchronized, see PromQuery.Entity.
+const (
+	EntityCluster   = "cluster"
+	EntityNode      = "node"
+	EntityPod       = "pod"
+	EntityContainer = "container"
+)
+
+// DefaultPromQueryInterval is the interval of queries that do not define their own.
+const DefaultPromQueryInterval = time.Minute
+
 // PromQuery defines a prometheus query with the metric group, the query and the name label
 type PromQuery struct {
-	metricCategory string
-	query          string
-	nameLabel      model.LabelName
+	// Entity is the kind of entity the results of the query are stored for, i.e. cluster, node, pod or container.
+	Entity string `yaml:"entity"`
+	// Category is the metric group, which identifies the query per entity.
+	Category string `yaml:"category"`
+	Query    string `yaml:"query"`
+	// NameLabel is the label of the results whose value is stored as metric name, if any.
+	NameLabel model.LabelName `yaml:"name_label"`
+	// NodeLabel is the label of the results of node queries that holds the node name. Defaults to node.
+	// Results without it are matched by the instance label, without port, instead.
+	NodeLabel model.LabelName `yaml:"node_label"`
+	// NamespaceLabel and PodLabel are the labels of the results of pod and container queries
+	// that hold the namespace and name of the pod. Default to namespace and pod.
+	NamespaceLabel model.LabelName `yaml:"namespace_label"`
+	PodLabel       model.LabelName `yaml:"pod_label"`
+	// ContainerLabel is the label of the results of container queries that holds the container name,
+	// in addition to NamespaceLabel and PodLabel. Defaults to container.
+	ContainerLabel model.LabelName `yaml:"container_label"`
+	// Interval is the interval the query is executed in. Defaults to DefaultPromQueryInterval.
+	Interval time.Duration `yaml:"interval"`
+	// Disabled removes a built-in query of the same entity and category.
+	Disabled bool `yaml:"disabled"`
+}
+
+// PromQueries returns the built-in queries merged with the given ones per entity.
+// Queries replace built-in queries of the same entity and category, or remove them if disabled.
+func PromQueries(custom []PromQuery) map[string][]PromQuery {
+	queries := map[string][]PromQuery{
+		EntityCluster:   slices.Clone(promQueriesCluster),
+		EntityNode:      slices.Clone(promQueriesNode),
+		EntityPod:       slices.Clone(promQueriesPod),
+		EntityContainer: slices.Clone(promQueriesContainer),
+	}
+
+	for _, query := range custom {
+		i := slices.IndexFunc(queries[query.Entity], func(q PromQuery) bool { return q.Category == query.Category })
+
+		switch {
+		case query.Disabled && i >= 0:
+			queries[query.Entity] = slices.Delete(queries[query.Entity], i, i+1)
+		case query.Disabled:
+			// There is no built-in query to remove.
+		case i >= 0:
+			queries[query.Entity][i] = query
+		default:
+			queries[query.Entity] = append(queries[query.Entity], query)
+		}
+	}
+
+	for entity := range queries {
+		for i := range queries[entity] {
+			queries[entity][i].Entity = entity
+			if queries[entity][i].Interval == 0 {
+				queries[entity][i].Interval = DefaultPromQueryInterval
+			}
+			if queries[entity][i].NodeLabel == "" {
+				queries[entity][i].NodeLabel = "node"
+			}
+			if queries[entity][i].NamespaceLabel == "" {
+				queries[entity][i].NamespaceLabel = "namespace"
+			}
+			if queries[entity][i].PodLabel == "" {
+				queries[entity][i].PodLabel = "pod"
+			}
+			if queries[entity][i].ContainerLabel == "" {
+				queries[entity][i].ContainerLabel = "container"
+			}
+		}
+	}
+
+	return queries
+}
+
+// nodeName returns the name of the node the given result of a node query belongs to,
+// i.e. the value of NodeLabel or otherwise the host of the instance label, or "" if there is none.
+func (q PromQuery) nodeName(metric model.Metric) string {
+	if name := string(metric[q.NodeLabel]); name != "" {
+		return name
+	}
+
+	instance := string(metric["instance"])
+	if strings.Contains(instance, ":") {
+		host, _, err := net.SplitHostPort(instance)
+		if err != nil {
+			return ""
+		}
+
+		return host
+	}
+
+	return instance
+}
+
+// podKey returns the namespace/name key of the pod the given result of a pod or container query belongs to,
+// or "" if the result has no PodLabel.
+func (q PromQuery) podKey(metric model.Metric) string {
+	if metric[q.PodLabel] == "" {
+		return ""
+	}
+
+	return kcache.NewObjectName(string(metric[q.NamespaceLabel]), string(metric[q.PodLabel])).String()
 }
 
 var (
 	promQueriesCluster = []PromQuery{
 		{
-			"node.count",
-			`count(group by (node) (kube_node_info))`,
-			"",
+			Category: "node.count",
+			Query:    `count(group by (node) (kube_node_info))`,
 		},
 		{
-			"namespace.count",
-			`count(kube_namespace_created)`,
-			"",
+			Category: "namespace.count",
+			Query:    `count(kube_namespace_created)`,
 		},
 		{
-			"pod.running",
-			`sum(kube_pod_status_phase{phase="Running"})`,
-			"",
+			Category: "pod.running",
+			Query:    `sum(kube_pod_status_phase{phase="Running"})`,
 		},
 		{
-			"pod.pending",
-			`sum(kube_pod_status_phase{phase="Pending"})`,
-			"",
+			Category: "pod.pending",
+			Query:    `sum(kube_pod_status_phase{phase="Pending"})`,
 		},
 		{
-			"pod.failed",
-			`sum(kube_pod_status_phase{phase="Failed"})`,
-			"",
+			Category: "pod.failed",
+			Query:    `sum(kube_pod_status_phase{phase="Failed"})`,
 		},
 		{
-			"pod.succeeded",
-			`sum(kube_pod_status_phase{phase="Succeeded"})`,
-			"",
+			Category: "pod.succeeded",
+			Query:    `sum(kube_pod_status_phase{phase="Succeeded"})`,
 		},
 		{
-			"cpu.usage",
-			`avg(sum by (instance, cpu) (rate(node_cpu_seconds_total{mode!~"idle|iowait|steal"}[1m])))`,
-			"",
+			Category: "cpu.usage",
+			Query:    `avg(sum by (instance, cpu) (rate(node_cpu_seconds_total{mode!~"idle|iowait|steal"}[1m])))`,
 		},
 		{
-			"memory.usage",
-			`sum(node_memory_MemTotal_bytes - node_memory_MemAvailable_bytes) / sum(node_memory_MemTotal_bytes)`,
-			"",
+			Category: "memory.usage",
+			Query:    `sum(node_memory_MemTotal_bytes - node_memory_MemAvailable_bytes) / sum(node_memory_MemTotal_bytes)`,
 		},
 		{
-			"qos_by_class",
-			`sum by (qos_class) (kube_pod_status_qos_class)`,
-			"",
+			Category: "qos_by_class",
+			Query:    `sum by (qos_class) (kube_pod_status_qos_class)`,
 		},
 		{
-			"network.received.bytes",
-			`sum by (device) (rate(node_network_receive_bytes_total{device!~"(veth|azv|lxc).*"}[2m]))`,
-			"",
+			Category: "network.received.bytes",
+			Query:    `sum by (device) (rate(node_network_receive_bytes_total{device!~"(veth|azv|lxc).*"}[2m]))`,
 		},
 		{
-			"network.transmitted.bytes",
-			`- sum by (device) (rate(node_network_transmit_bytes_total{device!~"(veth|azv|lxc).*"}[2m]))`,
-			"",
+			Category: "network.transmitted.bytes",
+			Query:    `- sum by (device) (rate(node_network_transmit_bytes_total{device!~"(veth|azv|lxc).*"}[2m]))`,
 		},
 		{
-			"network.received.bytes.bydevice",
-			`sum by (device) (rate(node_network_receive_bytes_total{device!~"(veth|azv|lxc).*"}[2m]))`,
-			"device",
+			Category:  "network.received.bytes.bydevice",
+			Query:     `sum by (device) (rate(node_network_receive_bytes_total{device!~"(veth|azv|lxc).*"}[2m]))`,
+			NameLabel: "device",
 		},
 	}
 
 	promQueriesNode = []PromQuery{
 		{
-			"cpu.usage",
-			`avg by (node) (sum by (node, cpu) (rate(node_cpu_seconds_total{mode!~"idle|iowait|steal"}[2m])))`,
+			Category: "cpu.usage",
+			Query:    `avg by (node) (sum by (node, cpu) (rate(node_cpu_seconds_total{mode!~"idle|iowait|steal"}[2m])))`,
 			// TODO(el): Check this alternative.
 			//`avg without (mode,cpu) (1 - rate(node_cpu_seconds_total{mode="idle"}[1m]))`,
-			"",
 		},
 		{
-			"cpu.request",
-			`sum by (node) (kube_pod_container_resource_requests{resource="cpu"})`,
-			"",
+			Category: "cpu.request",
+			Query:    `sum by (node) (kube_pod_container_resource_requests{resource="cpu"})`,
 		},
 		{
-			"cpu.request.percentage",
-			`sum by (node) (kube_pod_container_resource_requests{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
-			"",
+			Category: "cpu.request.percentage",
+			Query:    `sum by (node) (kube_pod_container_resource_requests{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
 		},
 		{
-			"cpu.limit",
-			`sum by (node) (kube_pod_container_resource_limits{resource="cpu"})`,
-			"",
+			Category: "cpu.limit",
+			Query:    `sum by (node) (kube_pod_container_resource_limits{resource="cpu"})`,
 		},
 		{
-			"cpu.limit.percentage",
-			`sum by (node) (kube_pod_container_resource_limits{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
-			"",
+			Category: "cpu.limit.percentage",
+			Query:    `sum by (node) (kube_pod_container_resource_limits{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
 		},
 		{
-			"memory.usage",
-			`sum by (instance) (node_memory_MemTotal_bytes - node_memory_MemAvailable_bytes) / sum by (instance) (node_memory_MemTotal_bytes)`,
-			"",
+			Category: "memory.usage",
+			Query:    `sum by (instance) (node_memory_MemTotal_bytes - node_memory_MemAvailable_bytes) / sum by (instance) (node_memory_MemTotal_bytes)`,
 		},
 		{
-			"memory.request",
-			`sum by (node) (kube_pod_container_resource_requests{resource="memory"})`,
-			"",
+			Category: "memory.request",
+			Query:    `sum by (node) (kube_pod_container_resource_requests{resource="memory"})`,
 		},
 		{
-			"memory.request.percentage",
-			`sum by (node) (kube_pod_container_resource_requests{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
-			"",
+			Category: "memory.request.percentage",
+			Query:    `sum by (node) (kube_pod_container_resource_requests{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
 		},
 		{
-			"memory.limit",
-			`sum by (node) (kube_pod_container_resource_limits{resource="memory"})`,
-			"",
+			Category: "memory.limit",
+			Query:    `sum by (node) (kube_pod_container_resource_limits{resource="memory"})`,
 		},
 		{
-			"memory.limit.percentage",
-			`sum by (node) (kube_pod_container_resource_limits{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
-			"",
+			Category: "memory.limit.percentage",
+			Query:    `sum by (node) (kube_pod_container_resource_limits{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
 		},
 		{
-			"network.received.bytes",
-			`sum by (node) (rate(node_network_receive_bytes_total[2m]))`,
-			"",
+			Category: "network.received.bytes",
+			Query:    `sum by (node) (rate(node_network_receive_bytes_total[2m]))`,
 		},
 		{
-			"network.transmitted.bytes",
-			`- sum by (node) (rate(node_network_transmit_bytes_total[2m]))`,
-			"",
+			Category: "network.transmitted.bytes",
+			Query:    `- sum by (node) (rate(node_network_transmit_bytes_total[2m]))`,
 		},
 		{
-			"filesystem.usage",
-			`sum by (node, mountpoint) (1 - (node_filesystem_avail_bytes / node_filesystem_size_bytes))`,
-			"mountpoint",
+			Category:  "filesystem.usage",
+			Query:     `sum by (node, mountpoint) (1 - (node_filesystem_avail_bytes / node_filesystem_size_bytes))`,
+			NameLabel: "mountpoint",
 		},
 	}
 
 	promQueriesPod = []PromQuery{
 		{
-			"cpu.usage",
-			`sum by (instance, namespace, pod) (rate(container_cpu_usage_seconds_total[2m]))`,
-			"",
+			Category: "cpu.usage",
+			Query:    `sum by (instance, namespace, pod) (rate(container_cpu_usage_seconds_total[2m]))`,
 		},
 		{
-			"memory.usage",
-			`sum by (instance, namespace, pod) (container_memory_usage_bytes) / on (instance) group_left () label_replace(sum by (node) (node_memory_MemTotal_bytes), "instance", "$1", "node", "(.*)")`,
-			"",
+			Category: "memory.usage",
+			Query:    `sum by (instance, namespace, pod) (container_memory_usage_bytes) / on (instance) group_left () label_replace(sum by (node) (node_memory_MemTotal_bytes), "instance", "$1", "node", "(.*)")`,
 		},
 		{
-			"cpu.usage.cores",
-			`sum by (namespace, pod) (rate(container_cpu_usage_seconds_total[2m]))`,
-			"",
+			Category: "cpu.usage.cores",
+			Query:    `sum by (namespace, pod) (rate(container_cpu_usage_seconds_total[2m]))`,
 		},
 		{
-			"memory.usage.bytes",
-			`sum by (namespace, pod) (container_memory_usage_bytes)`,
-			"",
+			Category: "memory.usage.bytes",
+			Query:    `sum by (namespace, pod) (container_memory_usage_bytes)`,
 		},
 		{
-			"cpu.request",
-			`sum by (node, namespace, pod) (kube_pod_container_resource_requests{resource="cpu"})`,
-			"",
+			Category: "cpu.request",
+			Query:    `sum by (node, namespace, pod) (kube_pod_container_resource_requests{resource="cpu"})`,
 		},
 		{
-			"cpu.request.percentage",
-			`sum by (node, namespace, pod) (kube_pod_container_resource_requests{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
-			"",
+			Category: "cpu.request.percentage",
+			Query:    `sum by (node, namespace, pod) (kube_pod_container_resource_requests{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
 		},
 		{
-			"cpu.limit",
-			`sum by (node, namespace, pod) (kube_pod_container_resource_limits{resource="cpu"})`,
-			"",
+			Category: "cpu.limit",
+			Query:    `sum by (node, namespace, pod) (kube_pod_container_resource_limits{resource="cpu"})`,
 		},
 		{
-			"cpu.limit.percentage",
-			`sum by (node, namespace, pod) (kube_pod_container_resource_limits{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
-			"",
+			Category: "cpu.limit.percentage",
+			Query:    `sum by (node, namespace, pod) (kube_pod_container_resource_limits{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
 		},
 		{
-			"memory.request",
-			`sum by (node, namespace, pod) (kube_pod_container_resource_requests{resource="memory"})`,
-			"",
+			Category: "memory.request",
+			Query:    `sum by (node, namespace, pod) (kube_pod_container_resource_requests{resource="memory"})`,
 		},
 		{
-			"memory.request.percentage",
-			`sum by (node, namespace, pod) (kube_pod_container_resource_requests{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
-			"",
+			Category: "memory.request.percentage",
+			Query:    `sum by (node, namespace, pod) (kube_pod_container_resource_requests{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
 		},
 		{
-			"memory.limit",
-			`sum by (node, namespace, pod) (kube_pod_container_resource_limits{resource="memory"})`,
-			"",
+			Category: "memory.limit",
+			Query:    `sum by (node, namespace, pod) (kube_pod_container_resource_limits{resource="memory"})`,
 		},
 		{
-			"memory.limit.percentage",
-			`sum by (node, namespace, pod) (kube_pod_container_resource_limits{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
-			"",
+			Category: "memory.limit.percentage",
+			Query:    `sum by (node, namespace, pod) (kube_pod_container_resource_limits{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
 		},
 	}
 
 	promQueriesContainer = []PromQuery{
 		{
-			"cpu.request",
-			`sum by (node, namespace, pod, container) (kube_pod_container_resource_requests{resource="cpu"})`,
-			"",
+			Category: "cpu.request",
+			Query:    `sum by (node, namespace, pod, container) (kube_pod_container_resource_requests{resource="cpu"})`,
 		},
 		{
-			"cpu.request.percentage",
-			`sum by (node, namespace, pod, container) (kube_pod_container_resource_requests{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
-			"",
+			Category: "cpu.request.percentage",
+			Query:    `sum by (node, namespace, pod, container) (kube_pod_container_resource_requests{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
 		},
 		{
-			"cpu.limit",
-			`sum by (node, namespace, pod, container) (kube_pod_container_resource_limits{resource="cpu"})`,
-			"",
+			Category: "cpu.limit",
+			Query:    `sum by (node, namespace, pod, container) (kube_pod_container_resource_limits{resource="cpu"})`,
 		},
 		{
-			"cpu.limit.percentage",
-			`sum by (node, namespace, pod, container) (kube_pod_container_resource_limits{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
-			"",
+			Category: "cpu.limit.percentage",
+			Query:    `sum by (node, namespace, pod, container) (kube_pod_container_resource_limits{resource="cpu"}) / on(node) group_left() (sum by (node) (machine_cpu_cores))`,
 		},
 		{
-			"memory.request",
-			`sum by (node, namespace, pod, container) (kube_pod_container_resource_requests{resource="memory"})`,
-			"",
+			Category: "memory.request",
+			Query:    `sum by (node, namespace, pod, container) (kube_pod_container_resource_requests{resource="memory"})`,
 		},
 		{
-			"memory.request.percentage",
-			`sum by (node, namespace, pod, container) (kube_pod_container_resource_requests{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
-			"",
+			Category: "memory.request.percentage",
+			Query:    `sum by (node, namespace, pod, container) (kube_pod_container_resource_requests{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
 		},
 		{
-			"memory.limit",
-			`sum by (node, namespace, pod, container) (kube_pod_container_resource_limits{resource="memory"})`,
-			"",
+			Category: "memory.limit",
+			Query:    `sum by (node, namespace, pod, container) (kube_pod_container_resource_limits{resource="memory"})`,
 		},
 		{
-			"memory.limit.percentage",
-			`sum by (node, namespace, pod, container) (kube_pod_container_resource_limits{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
-			"",
+			Category: "memory.limit.percentage",
+			Query:    `sum by (node, namespace, pod, container) (kube_pod_container_resource_limits{resource="memory"}) / on(node) group_left() (sum by (node) (machine_memory_bytes))`,
 		},
 	}
 )
@@ -276,59 +343,45 @@ type PromMetricSync struct {
 	promApiClient v1.API
 	db            *database.DB
 	logger        *logging.Logger
+	queries       map[string][]PromQuery
 }
 
-// NewPromMetricSync creates a new PromMetricSync
-func NewPromMetricSync(promApiClient v1.API, db *database.DB, logger *logging.Logger) *PromMetricSync {
+// NewPromMetricSync creates a new PromMetricSync executing the given queries per entity, see PromQueries.
+func NewPromMetricSync(
+	promApiClient v1.API, db *database.DB, logger *logging.Logger, queries map[string][]PromQuery,
+) *PromMetricSync {
 	return &PromMetricSync{
 		promApiClient: promApiClient,
 		db:            db,
 		logger:        logger,
+		queries:       queries,
 	}
 }
 
+// promMetricUpsertStmt returns database upsert statement to upsert metrics into the given table,
+// whose primary key consists of idColumn, timestamp, category and name.
+func (pms *PromMetricSync) promMetricUpsertStmt(table, idColumn string) string {
+	return metricUpsertStmt(pms.db, table, idColumn)
+}
+
 // promMetricClusterUpsertStmt returns database upsert statement to upsert cluster metrics
 func (pms *PromMetricSync) promMetricClusterUpsertStmt() string {
-	return fmt.Sprintf(
-		`INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s`,
-		`prometheus_cluster_metric`,
-		"cluster_id, timestamp, category, name, value",
-		`:cluster_id, :timestamp, :category, :name, :value`,
-		`value=VALUES(value)`,
-	)
+	return pms.promMetricUpsertStmt("prometheus_cluster_metric", "cluster_uuid")
 }
 
 // promMetricNodeUpsertStmt returns database upsert statement to upsert node metrics
 func (pms *PromMetricSync) promMetricNodeUpsertStmt() string {
-	return fmt.Sprintf(
-		`INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s`,
-		`prometheus_node_metric`,
-		"node_uuid, timestamp, category, name, value",
-		`:node_uuid, :timestamp, :category, :name, :value`,
-		`value=VALUES(value)`,
-	)
+	return pms.promMetricUpsertStmt("prometheus_node_metric", "node_uuid")
 }
 
 // promMetricPodUpsertStmt returns database upsert statement to upsert pod metrics
 func (pms *PromMetricSync) promMetricPodUpsertStmt() string {
-	return fmt.Sprintf(
-		`INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s`,
-		`prometheus_pod_metric`,
-		"pod_uuid, timestamp, category, name, value",
-		`:pod_uuid, :timestamp, :category, :name, :value`,
-		`value=VALUES(value)`,
-	)
+	return pms.promMetricUpsertStmt("prometheus_pod_metric", "pod_uuid")
 }
 
 // promMetricContainerUpsertStmt returns database upsert statement to upsert container metrics
 func (pms *PromMetricSync) promMetricContainerUpsertStmt() string {
-	return fmt.Sprintf(
-		`INSERT INTO %s (%s) VALUES (%s) ON DUPLICATE KEY UPDATE %s`,
-		`prometheus_container_metric`,
-		"container_id, timestamp, category, name, value",
-		`:container_id, :timestamp, :category, :name, :value`,
-		`value=VALUES(value)`,
-	)
+	return pms.promMetricUpsertStmt("prometheus_container_metric", "container_uuid")
 }
 
 func (pms *PromMetricSync) run(
@@ -351,11 +404,17 @@ func (pms *PromMetricSync) run(
 				err := retry.WithBackoff(
 					ctx,
 					func(ctx context.Context) error {
+						started := time.Now()
 						result, warnings, err = pms.promApiClient.Query(
 							ctx,
-							promQuery.query,
+							promQuery.Query,
 							time.Time{},
 						)
+						if err == nil {
+							telemetry.PrometheusQueryDuration.
+								WithLabelValues(promQuery.Category).
+								Observe(time.Since(started).Seconds())
+						}
 
 						return err
 					},
@@ -404,7 +463,7 @@ func (pms *PromMetricSync) run(
 				}
 
 				select {
-				case <-time.After(time.Second * 60):
+				case <-time.After(promQuery.Interval):
 				case <-ctx.Done():
 					return ctx.Err()
 				}
@@ -441,39 +500,27 @@ func (pms *PromMetricSync) Nodes(ctx context.Context, informer kcache.SharedInde
 	g.Go(func() error {
 		return pms.run(
 			ctx,
-			promQueriesNode,
+			pms.queries[EntityNode],
 			upsertMetrics,
 			func(query PromQuery, res *model.Sample) database.Entity {
 				if res.Value.String() == "NaN" {
 					return nil
 				}
 
-				nodeName := string(res.Metric["node"])
-				if nodeName == "" {
-					if strings.Contains(string(res.Metric["instance"]), ":") {
-						if host, _, err := net.SplitHostPort(string(res.Metric["instance"])); err == nil {
-							nodeName = host
-						} else {
-							return nil
-						}
-					} else {
-						nodeName = string(res.Metric["instance"])
-					}
-				}
-				uuid, exists := nodes.Load(nodeName)
+				uuid, exists := nodes.Load(query.nodeName(res.Metric))
 				if !exists {
 					return nil
 				}
 
 				name := ""
-				if query.nameLabel != "" {
-					name = string(res.Metric[query.nameLabel])
+				if query.NameLabel != "" {
+					name = string(res.Metric[query.NameLabel])
 				}
 
 				newNodeMetric := &schemav1.PrometheusNodeMetric{
 					NodeUuid:  uuid.(types.UUID),
 					Timestamp: (res.Timestamp.UnixNano() - res.Timestamp.UnixNano()%(60*1000000000)) / 1000000,
-					Category:  query.metricCategory,
+					Category:  query.Category,
 					Name:      name,
 					Value:     float64(res.Value),
 				}
@@ -490,9 +537,13 @@ func (pms *PromMetricSync) Nodes(ctx context.Context, informer kcache.SharedInde
 	return g.Wait()
 }
 
-func (pms *PromMetricSync) Pods(ctx context.Context, informer kcache.SharedIndexInformer) error {
-	if !kcache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
-		return errors.New("timed out waiting for caches to sync")
+// Pods synchronizes the metrics of all pods known to any of the given informers,
+// which may each be restricted to a single namespace.
+func (pms *PromMetricSync) Pods(ctx context.Context, informers ...kcache.SharedIndexInformer) error {
+	for _, informer := range informers {
+		if !kcache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			return errors.New("timed out waiting for caches to sync")
+		}
 	}
 
 	upsertMetrics := make(chan database.Entity)
@@ -502,33 +553,39 @@ func (pms *PromMetricSync) Pods(ctx context.Context, informer kcache.SharedIndex
 	g.Go(func() error {
 		return pms.run(
 			ctx,
-			promQueriesPod,
+			pms.queries[EntityPod],
 			upsertMetrics,
 			func(query PromQuery, res *model.Sample) database.Entity {
-				if res.Metric["pod"] == "" {
+				key := query.podKey(res.Metric)
+				if key == "" {
 					return nil
 				}
 
-				obj, exists, err := informer.GetStore().GetByKey(
-					kcache.NewObjectName(string(res.Metric["namespace"]), string(res.Metric["pod"])).String())
-				if err != nil {
-					//return errors.Wrap(err, "cannot get pod from store")
-					return nil
+				var pod *kcorev1.Pod
+				for _, informer := range informers {
+					obj, exists, err := informer.GetStore().GetByKey(key)
+					if err != nil {
+						//return errors.Wrap(err, "cannot get pod from store")
+						return nil
+					}
+					if exists {
+						pod = obj.(*kcorev1.Pod)
+						break
+					}
 				}
-				if !exists {
+				if pod == nil {
 					return nil
 				}
-				pod := obj.(*kcorev1.Pod)
 
 				name := ""
-				if query.nameLabel != "" {
-					name = string(res.Metric[query.nameLabel])
+				if query.NameLabel != "" {
+					name = string(res.Metric[query.NameLabel])
 				}
 
 				newPodMetric := &schemav1.PrometheusPodMetric{
 					PodUuid:   schemav1.EnsureUUID(pod.UID),
 					Timestamp: (res.Timestamp.UnixNano() - res.Timestamp.UnixNano()%(60*1000000000)) / 1000000,
-					Category:  query.metricCategory,
+					Category:  query.Category,
 					Name:      name,
 					Value:     float64(res.Value),
 				}
@@ -545,9 +602,13 @@ func (pms *PromMetricSync) Pods(ctx context.Context, informer kcache.SharedIndex
 	return g.Wait()
 }
 
-func (pms *PromMetricSync) Containers(ctx context.Context, informer kcache.SharedIndexInformer) error {
-	if !kcache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
-		pms.logger.Fatal("timed out waiting for caches to sync")
+// Containers synchronizes the metrics of the containers of all pods known to any of the given informers,
+// which may each be restricted to a single namespace.
+func (pms *PromMetricSync) Containers(ctx context.Context, informers ...kcache.SharedIndexInformer) error {
+	for _, informer := range informers {
+		if !kcache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			return errors.New("timed out waiting for caches to sync")
+		}
 	}
 
 	upsertMetrics := make(chan database.Entity)
@@ -557,27 +618,45 @@ func (pms *PromMetricSync) Containers(ctx context.Context, informer kcache.Share
 	g.Go(func() error {
 		return pms.run(
 			ctx,
-			promQueriesContainer,
+			pms.queries[EntityContainer],
 			upsertMetrics,
 			func(query PromQuery, res *model.Sample) database.Entity {
 				if res.Value.String() == "NaN" {
 					return nil
 				}
 
-				//containerId := sha1.Sum([]byte(res.Metric["namespace"] + "/" + res.Metric["pod"] + "/" + res.Metric["container"]))
+				key := query.podKey(res.Metric)
+				container := string(res.Metric[query.ContainerLabel])
+				if key == "" || container == "" {
+					return nil
+				}
+
+				var pod *kcorev1.Pod
+				for _, informer := range informers {
+					obj, exists, err := informer.GetStore().GetByKey(key)
+					if err != nil {
+						return nil
+					}
+					if exists {
+						pod = obj.(*kcorev1.Pod)
+						break
+					}
+				}
+				if pod == nil {
+					return nil
+				}
 
 				name := ""
-
-				if query.nameLabel != "" {
-					name = string(res.Metric[query.nameLabel])
+				if query.NameLabel != "" {
+					name = string(res.Metric[query.NameLabel])
 				}
 
 				newContainerMetric := &schemav1.PrometheusContainerMetric{
-					// TODO uuid
-					Timestamp: (res.Timestamp.UnixNano() - res.Timestamp.UnixNano()%(60*1000000000)) / 1000000,
-					Category:  query.metricCategory,
-					Name:      name,
-					Value:     float64(res.Value),
+					ContainerUuid: schemav1.NewUUID(schemav1.EnsureUUID(pod.UID), container),
+					Timestamp:     (res.Timestamp.UnixNano() - res.Timestamp.UnixNano()%(60*1000000000)) / 1000000,
+					Category:      query.Category,
+					Name:          name,
+					Value:         float64(res.Value),
 				}
 
 				return newContainerMetric
@@ -592,10 +671,9 @@ func (pms *PromMetricSync) Containers(ctx context.Context, informer kcache.Share
 	return g.Wait()
 }
 
-func (pms *PromMetricSync) Clusters(ctx context.Context, informer kcache.SharedIndexInformer) error {
-	if !kcache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
-		pms.logger.Fatal("timed out waiting for caches to sync")
-	}
+// Clusters synchronizes the metrics of the cluster stored in ctx, see cluster.NewClusterUuidContext.
+func (pms *PromMetricSync) Clusters(ctx context.Context) error {
+	clusterUuid := cluster.ClusterUuidFromContext(ctx)
 
 	upsertMetrics := make(chan database.Entity)
 
@@ -604,27 +682,25 @@ func (pms *PromMetricSync) Clusters(ctx context.Context, informer kcache.SharedI
 	g.Go(func() error {
 		return pms.run(
 			ctx,
-			promQueriesCluster,
+			pms.queries[EntityCluster],
 			upsertMetrics,
 			func(query PromQuery, res *model.Sample) database.Entity {
 				if res.Value.String() == "NaN" {
 					return nil
 				}
 
-				//clusterId := sha1.Sum([]byte(""))
-
 				name := ""
 
-				if query.nameLabel != "" {
-					name = string(res.Metric[query.nameLabel])
+				if query.NameLabel != "" {
+					name = string(res.Metric[query.NameLabel])
 				}
 
 				newClusterMetric := &schemav1.PrometheusClusterMetric{
-					// TODO uuid
-					Timestamp: (res.Timestamp.UnixNano() - res.Timestamp.UnixNano()%(60*1000000000)) / 1000000,
-					Category:  query.metricCategory,
-					Name:      name,
-					Value:     float64(res.Value),
+					ClusterUuid: clusterUuid,
+					Timestamp:   (res.Timestamp.UnixNano() - res.Timestamp.UnixNano()%(60*1000000000)) / 1000000,
+					Category:    query.Category,
+					Name:        name,
+					Value:       float64(res.Value),
 				}
 
 				return newClusterMetric
@@ -0,0 +1,249 @@
+package metrics
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/icinga/icinga-go-library/database"
+	"github.com/icinga/icinga-go-library/logging"
+	schemav1 "github.com/icinga/icinga-kubernetes/pkg/schema/v1"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+	"golang.org/x/sync/errgroup"
+	kcorev1 "k8s.io/api/core/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kcache "k8s.io/client-go/tools/cache"
+)
+
+// metricsApiGroupVersion is the group version of the resource metrics API served by metrics-server.
+const metricsApiGroupVersion = "metrics.k8s.io/v1beta1"
+
+// metricsServerInterval is the interval in which metrics are fetched from the resource metrics API.
+const metricsServerInterval = time.Minute
+
+// nodeMetrics is a NodeMetrics object of the resource metrics API.
+// Objects of the API are decoded into own types, so that k8s.io/metrics is not required.
+type nodeMetrics struct {
+	kmetav1.ObjectMeta `json:"metadata"`
+	Timestamp          kmetav1.Time         `json:"timestamp"`
+	Usage              kcorev1.ResourceList `json:"usage"`
+}
+
+// podMetrics is a PodMetrics object of the resource metrics API.
+type podMetrics struct {
+	kmetav1.ObjectMeta `json:"metadata"`
+	Timestamp          kmetav1.Time `json:"timestamp"`
+	Containers         []struct {
+		Name  string               `json:"name"`
+		Usage kcorev1.ResourceList `json:"usage"`
+	} `json:"containers"`
+}
+
+// MetricsServerSync synchronizes the CPU and memory usage of nodes and pods from the resource metrics API,
+// i.e. metrics-server, to the database. It writes the same categories as the corresponding Prometheus queries,
+// so that it can be used if no Prometheus is available.
+type MetricsServerSync struct {
+	clientset kubernetes.Interface
+	db        *database.DB
+	logger    *logging.Logger
+
+	// nodeMemory holds the memory capacity per node name, which the memory usage of pods is related to.
+	nodeMemory sync.Map
+}
+
+// NewMetricsServerSync creates a new MetricsServerSync.
+func NewMetricsServerSync(clientset kubernetes.Interface, db *database.DB, logger *logging.Logger) *MetricsServerSync {
+	return &MetricsServerSync{
+		clientset: clientset,
+		db:        db,
+		logger:    logger,
+	}
+}
+
+// MetricsServerAvailable returns whether the resource metrics API is served in the cluster.
+func MetricsServerAvailable(clientset kubernetes.Interface) bool {
+	_, err := clientset.Discovery().ServerResourcesForGroupVersion(metricsApiGroupVersion)
+
+	return err == nil
+}
+
+// listMetrics fetches all objects of the given resource of the resource metrics API.
+func listMetrics[T any](ctx context.Context, clientset kubernetes.Interface, resource string) ([]T, error) {
+	raw, err := clientset.Discovery().RESTClient().Get().AbsPath("/apis", metricsApiGroupVersion, resource).Do(ctx).Raw()
+	if err != nil {
+		return nil, errors.Wrapf(err, "cannot list %s metrics", resource)
+	}
+
+	var list struct {
+		Items []T `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &list); err != nil {
+		return nil, errors.Wrapf(err, "cannot decode %s metrics", resource)
+	}
+
+	return list.Items, nil
+}
+
+// poll calls the given function immediately and then every metricsServerInterval until ctx is canceled.
+// Errors are logged, as metrics are fetched again in the next interval anyway.
+func (mss *MetricsServerSync) poll(ctx context.Context, fn func(context.Context) error) error {
+	for {
+		if err := fn(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			mss.logger.Warnw("Cannot synchronize metrics from metrics API", zap.Error(err))
+		}
+
+		select {
+		case <-time.After(metricsServerInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Nodes implements the Source interface.
+func (mss *MetricsServerSync) Nodes(ctx context.Context, informer kcache.SharedIndexInformer) error {
+	if !kcache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return errors.New("timed out waiting for caches to sync")
+	}
+
+	upsertMetrics := make(chan database.Entity)
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return mss.poll(ctx, func(ctx context.Context) error {
+			items, err := listMetrics[nodeMetrics](ctx, mss.clientset, "nodes")
+			if err != nil {
+				return err
+			}
+
+			for _, item := range items {
+				obj, exists, err := informer.GetStore().GetByKey(item.Name)
+				if err != nil || !exists {
+					continue
+				}
+				node := obj.(*kcorev1.Node)
+
+				memoryCapacity := node.Status.Capacity.Memory().AsApproximateFloat64()
+				mss.nodeMemory.Store(node.Name, memoryCapacity)
+
+				usages := map[string]float64{}
+				if cpuCapacity := node.Status.Capacity.Cpu().AsApproximateFloat64(); cpuCapacity > 0 {
+					usages["cpu.usage"] = item.Usage.Cpu().AsApproximateFloat64() / cpuCapacity
+				}
+				if memoryCapacity > 0 {
+					usages["memory.usage"] = item.Usage.Memory().AsApproximateFloat64() / memoryCapacity
+				}
+
+				for category, value := range usages {
+					select {
+					case upsertMetrics <- &schemav1.PrometheusNodeMetric{
+						NodeUuid:  schemav1.EnsureUUID(node.UID),
+						Timestamp: minute(item.Timestamp.Time),
+						Category:  category,
+						Value:     value,
+					}:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+
+			return nil
+		})
+	})
+
+	g.Go(func() error {
+		return database.NewUpsert(mss.db, database.WithStatement(
+			metricUpsertStmt(mss.db, "prometheus_node_metric", "node_uuid"), 5)).Stream(ctx, upsertMetrics)
+	})
+
+	return g.Wait()
+}
+
+// Pods implements the Source interface.
+// The memory usage of pods is only related to the memory capacity of their nodes if nodes are synchronized as well.
+func (mss *MetricsServerSync) Pods(ctx context.Context, informers ...kcache.SharedIndexInformer) error {
+	for _, informer := range informers {
+		if !kcache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+			return errors.New("timed out waiting for caches to sync")
+		}
+	}
+
+	upsertMetrics := make(chan database.Entity)
+
+	g, ctx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		return mss.poll(ctx, func(ctx context.Context) error {
+			items, err := listMetrics[podMetrics](ctx, mss.clientset, "pods")
+			if err != nil {
+				return err
+			}
+
+			for _, item := range items {
+				var pod *kcorev1.Pod
+				for _, informer := range informers {
+					obj, exists, err := informer.GetStore().GetByKey(
+						kcache.NewObjectName(item.Namespace, item.Name).String())
+					if err == nil && exists {
+						pod = obj.(*kcorev1.Pod)
+						break
+					}
+				}
+				if pod == nil {
+					continue
+				}
+
+				var cpu, memory float64
+				for _, container := range item.Containers {
+					cpu += container.Usage.Cpu().AsApproximateFloat64()
+					memory += container.Usage.Memory().AsApproximateFloat64()
+				}
+
+				usages := map[string]float64{
+					"cpu.usage":          cpu,
+					"cpu.usage.cores":    cpu,
+					"memory.usage.bytes": memory,
+				}
+				if capacity, ok := mss.nodeMemory.Load(pod.Spec.NodeName); ok && capacity.(float64) > 0 {
+					usages["memory.usage"] = memory / capacity.(float64)
+				}
+
+				for category, value := range usages {
+					select {
+					case upsertMetrics <- &schemav1.PrometheusPodMetric{
+						PodUuid:   schemav1.EnsureUUID(pod.UID),
+						Timestamp: minute(item.Timestamp.Time),
+						Category:  category,
+						Value:     value,
+					}:
+					case <-ctx.Done():
+						return ctx.Err()
+					}
+				}
+			}
+
+			return nil
+		})
+	})
+
+	g.Go(func() error {
+		return database.NewUpsert(mss.db, database.WithStatement(
+			metricUpsertStmt(mss.db, "prometheus_pod_metric", "pod_uuid"), 5)).Stream(ctx, upsertMetrics)
+	})
+
+	return g.Wait()
+}
+
+// minute returns the given time truncated to the minute in milliseconds, like the timestamps of Prometheus metrics.
+func minute(t time.Time) int64 {
+	return t.Truncate(time.Minute).UnixMilli()
+}
@@ -0,0 +1,173 @@
+package metrics
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+func TestPromQueries(t *testing.T) {
+	find := func(queries []PromQuery, category string) (PromQuery, bool) {
+		i := slices.IndexFunc(queries, func(q PromQuery) bool { return q.Category == category })
+		if i < 0 {
+			return PromQuery{}, false
+		}
+
+		return queries[i], true
+	}
+
+	t.Run("builtin", func(t *testing.T) {
+		queries := PromQueries(nil)
+
+		for entity, builtin := range map[string][]PromQuery{
+			EntityCluster:   promQueriesCluster,
+			EntityNode:      promQueriesNode,
+			EntityPod:       promQueriesPod,
+			EntityContainer: promQueriesContainer,
+		} {
+			if len(queries[entity]) != len(builtin) {
+				t.Errorf("got %d %s queries, want %d", len(queries[entity]), entity, len(builtin))
+			}
+
+			for _, q := range queries[entity] {
+				if q.Entity != entity {
+					t.Errorf("query %q of %s has entity %q", q.Category, entity, q.Entity)
+				}
+				if q.Interval != DefaultPromQueryInterval {
+					t.Errorf("query %q of %s has interval %s, want %s", q.Category, entity, q.Interval, DefaultPromQueryInterval)
+				}
+				if q.NodeLabel != "node" || q.NamespaceLabel != "namespace" || q.PodLabel != "pod" ||
+					q.ContainerLabel != "container" {
+					t.Errorf("query %q of %s has labels %q, %q, %q and %q, want defaults",
+						q.Category, entity, q.NodeLabel, q.NamespaceLabel, q.PodLabel, q.ContainerLabel)
+				}
+			}
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		queries := PromQueries([]PromQuery{{
+			Entity: EntityNode, Category: "memory.usage", Query: "custom", Interval: 5 * time.Minute, NodeLabel: "host",
+		}})
+
+		if len(queries[EntityNode]) != len(promQueriesNode) {
+			t.Errorf("got %d node queries, want %d", len(queries[EntityNode]), len(promQueriesNode))
+		}
+
+		q, ok := find(queries[EntityNode], "memory.usage")
+		if !ok {
+			t.Fatal("memory.usage query of nodes missing")
+		}
+		if q.Query != "custom" || q.Interval != 5*time.Minute || q.NodeLabel != "host" {
+			t.Errorf("got query %q with interval %s and node label %q, want the custom one", q.Query, q.Interval, q.NodeLabel)
+		}
+
+		if q, _ := find(queries[EntityPod], "memory.usage"); q.Query == "custom" {
+			t.Error("memory.usage query of pods must not be replaced")
+		}
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		queries := PromQueries([]PromQuery{
+			{Entity: EntityCluster, Category: "qos_by_class", Disabled: true},
+			{Entity: EntityCluster, Category: "unknown", Disabled: true},
+		})
+
+		if len(queries[EntityCluster]) != len(promQueriesCluster)-1 {
+			t.Errorf("got %d cluster queries, want %d", len(queries[EntityCluster]), len(promQueriesCluster)-1)
+		}
+		if _, ok := find(queries[EntityCluster], "qos_by_class"); ok {
+			t.Error("disabled query qos_by_class not removed")
+		}
+		if _, ok := find(queries[EntityCluster], "unknown"); ok {
+			t.Error("disabled query without built-in query added")
+		}
+	})
+
+	t.Run("append", func(t *testing.T) {
+		queries := PromQueries([]PromQuery{{Entity: EntityPod, Category: "restarts", Query: "custom"}})
+
+		if len(queries[EntityPod]) != len(promQueriesPod)+1 {
+			t.Errorf("got %d pod queries, want %d", len(queries[EntityPod]), len(promQueriesPod)+1)
+		}
+
+		q, ok := find(queries[EntityPod], "restarts")
+		if !ok {
+			t.Fatal("added query restarts missing")
+		}
+		if q.Entity != EntityPod || q.Interval != DefaultPromQueryInterval || q.PodLabel != "pod" {
+			t.Errorf("added query has entity %q, interval %s and pod label %q, want defaults", q.Entity, q.Interval, q.PodLabel)
+		}
+	})
+
+	t.Run("builtin-unchanged", func(t *testing.T) {
+		PromQueries([]PromQuery{{Entity: EntityNode, Category: "cpu.usage", Query: "custom"}})
+
+		if q, _ := find(promQueriesNode, "cpu.usage"); q.Query == "custom" {
+			t.Error("built-in queries modified")
+		}
+	})
+}
+
+func TestPromQueryNodeName(t *testing.T) {
+	tests := []struct {
+		name   string
+		label  model.LabelName
+		metric model.Metric
+		want   string
+	}{
+		{name: "node", label: "node", metric: model.Metric{"node": "n1", "instance": "10.0.0.1:9100"}, want: "n1"},
+		{name: "instance", label: "node", metric: model.Metric{"instance": "10.0.0.1:9100"}, want: "10.0.0.1"},
+		{name: "instance-without-port", label: "node", metric: model.Metric{"instance": "n1"}, want: "n1"},
+		{name: "instance-ipv6", label: "node", metric: model.Metric{"instance": "[fd00::1]:9100"}, want: "fd00::1"},
+		{name: "instance-invalid", label: "node", metric: model.Metric{"instance": "a:b:c"}, want: ""},
+		{name: "custom", label: "host", metric: model.Metric{"host": "n2", "node": "n1"}, want: "n2"},
+		{name: "none", label: "node", metric: model.Metric{}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := (PromQuery{NodeLabel: tt.label}).nodeName(tt.metric); got != tt.want {
+				t.Errorf("nodeName(%v) = %q, want %q", tt.metric, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPromQueryPodKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  PromQuery
+		metric model.Metric
+		want   string
+	}{
+		{
+			name:   "default",
+			query:  PromQuery{NamespaceLabel: "namespace", PodLabel: "pod"},
+			metric: model.Metric{"namespace": "default", "pod": "web"},
+			want:   "default/web",
+		},
+		{
+			name:   "custom",
+			query:  PromQuery{NamespaceLabel: "exported_namespace", PodLabel: "exported_pod"},
+			metric: model.Metric{"namespace": "monitoring", "pod": "ksm", "exported_namespace": "default", "exported_pod": "web"},
+			want:   "default/web",
+		},
+		{
+			name:   "no-pod",
+			query:  PromQuery{NamespaceLabel: "namespace", PodLabel: "pod"},
+			metric: model.Metric{"namespace": "default"},
+			want:   "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.podKey(tt.metric); got != tt.want {
+				t.Errorf("podKey(%v) = %q, want %q", tt.metric, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/icinga/icinga-go-library/database"
+	"github.com/icinga/icinga-go-library/logging"
+	"github.com/icinga/icinga-go-library/periodic"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+)
+
+// MetricTable is a table of metrics, whose samples are rolled up into the tables of Rollups.
+type MetricTable struct {
+	Name string
+	// IdColumn references the entity of the metrics.
+	IdColumn string
+}
+
+// MetricTables lists all tables of metrics.
+var MetricTables = []MetricTable{
+	{Name: "prometheus_cluster_metric", IdColumn: "cluster_uuid"},
+	{Name: "prometheus_node_metric", IdColumn: "node_uuid"},
+	{Name: "prometheus_pod_metric", IdColumn: "pod_uuid"},
+	{Name: "prometheus_container_metric", IdColumn: "container_uuid"},
+	{Name: "pvc_metric", IdColumn: "pvc_uuid"},
+}
+
+// Rollup is a coarser resolution metrics are aggregated into. For each metric table, there is a table
+// <table>_<suffix> with the minimum, average and maximum of the samples in each interval of the resolution.
+type Rollup struct {
+	Suffix     string
+	Resolution time.Duration
+}
+
+// Table returns the name of the table the metrics of the given table are rolled up into.
+func (r Rollup) Table(t MetricTable) string {
+	return t.Name + "_" + r.Suffix
+}
+
+// Rollups lists the rollups from fine to coarse. Each rollup is aggregated from the previous one,
+// and the first from the metric tables.
+var Rollups = []Rollup{
+	{Suffix: "5m", Resolution: 5 * time.Minute},
+	{Suffix: "1h", Resolution: time.Hour},
+}
+
+// rollupChunk is the number of intervals aggregated per statement when catching up on startup.
+const rollupChunk = 24
+
+// RollupSync periodically aggregates the samples of the metric tables into the tables of Rollups.
+type RollupSync struct {
+	db        *database.DB
+	logger    *logging.Logger
+	retention func(table string) time.Duration
+}
+
+// NewRollupSync creates a new RollupSync. retention returns the retention of the given table, or zero
+// if it is kept forever, which limits how far back missing rollups are aggregated on startup.
+func NewRollupSync(db *database.DB, logger *logging.Logger, retention func(table string) time.Duration) *RollupSync {
+	return &RollupSync{
+		db:        db,
+		logger:    logger,
+		retention: retention,
+	}
+}
+
+// Run aggregates each rollup in the interval of its resolution until ctx is canceled.
+// Besides the intervals completed since the last run, the one before is aggregated again to include late samples.
+// On startup, all intervals still covered by the source tables are aggregated,
+// but not further back than the retention of the rollup if the source tables are kept forever.
+// The rollups are aggregated one after another, so that each one reads its source only after it is filled.
+// Intervals that cannot be aggregated are retried in the next run, and coarser rollups wait for them.
+func (rs *RollupSync) Run(ctx context.Context) error {
+	// done holds the end up to which the intervals of each table have been aggregated per rollup.
+	done := make([]map[string]time.Time, len(Rollups))
+	for i := range done {
+		done[i] = make(map[string]time.Time)
+	}
+
+	defer periodic.Start(ctx, Rollups[0].Resolution, func(tick periodic.Tick) {
+		for i, rollup := range Rollups {
+			var source *Rollup
+			if i > 0 {
+				source = &Rollups[i-1]
+			}
+
+			for _, table := range MetricTables {
+				to := tick.Time.Truncate(rollup.Resolution)
+				if source != nil {
+					// Intervals are only aggregated once their source has been aggregated completely.
+					to = minTime(to, done[i-1][table.Name].Truncate(rollup.Resolution))
+				}
+
+				last := done[i][table.Name]
+				if !to.After(last) {
+					continue
+				}
+
+				var from time.Time
+				if last.IsZero() {
+					from = to.Add(-2 * rollup.Resolution)
+					if catchUp := rs.catchUp(table, source, rollup); catchUp > 0 {
+						from = to.Add(-catchUp).Truncate(rollup.Resolution)
+					}
+				} else {
+					from = last.Add(-rollup.Resolution)
+				}
+
+				for start := from; start.Before(to); start = start.Add(rollupChunk * rollup.Resolution) {
+					end := minTime(start.Add(rollupChunk*rollup.Resolution), to)
+
+					if err := rs.rollup(ctx, table, source, rollup, start, end); err != nil {
+						if ctx.Err() == nil {
+							rs.logger.Warnw("Cannot roll up metrics", zap.String("table", rollup.Table(table)), zap.Error(err))
+						}
+
+						break
+					}
+
+					done[i][table.Name] = end
+				}
+			}
+		}
+	}, periodic.Immediate()).Stop()
+
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+// catchUp returns how far back the given rollup of the given table is aggregated on startup,
+// i.e. the retention of its source, or of the rollup itself if the source is kept forever.
+func (rs *RollupSync) catchUp(table MetricTable, source *Rollup, rollup Rollup) time.Duration {
+	sourceTable := table.Name
+	if source != nil {
+		sourceTable = source.Table(table)
+	}
+
+	if retention := rs.retention(sourceTable); retention > 0 {
+		return retention
+	}
+
+	return rs.retention(rollup.Table(table))
+}
+
+// rollup aggregates the samples of the given table in [from, to) from the given source rollup,
+// or the metric table itself if nil, into the given rollup.
+func (rs *RollupSync) rollup(
+	ctx context.Context, table MetricTable, source *Rollup, rollup Rollup, from, to time.Time,
+) error {
+	sourceTable := table.Name
+	aggregates := "MIN(value), AVG(value), MAX(value)"
+	if source != nil {
+		sourceTable = source.Table(table)
+		aggregates = "MIN(min), AVG(avg), MAX(max)"
+	}
+
+	targetTable := rollup.Table(table)
+
+	var clause string
+	switch rs.db.DriverName() {
+	case database.PostgreSQL:
+		clause = fmt.Sprintf(
+			`ON CONFLICT ON CONSTRAINT pk_%s DO UPDATE SET min = EXCLUDED.min, avg = EXCLUDED.avg, max = EXCLUDED.max`,
+			targetTable)
+	default:
+		clause = `ON DUPLICATE KEY UPDATE min = VALUES(min), avg = VALUES(avg), max = VALUES(max)`
+	}
+
+	bucket := fmt.Sprintf("timestamp - timestamp %% %d", rollup.Resolution.Milliseconds())
+	stmt := fmt.Sprintf(
+		`INSERT INTO %[1]s (%[2]s, timestamp, category, name, min, avg, max)`+
+			` SELECT %[2]s, %[3]s, category, name, %[4]s FROM %[5]s WHERE timestamp >= ? AND timestamp < ?`+
+			` GROUP BY %[2]s, %[3]s, category, name %[6]s`,
+		targetTable, table.IdColumn, bucket, aggregates, sourceTable, clause,
+	)
+
+	if _, err := rs.db.ExecContext(ctx, rs.db.Rebind(stmt), from.UnixMilli(), to.UnixMilli()); err != nil {
+		return errors.Wrapf(err, "cannot roll up %s into %s", sourceTable, targetTable)
+	}
+
+	return nil
+}
+
+// minTime returns the earlier of the given times.
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+
+	return b
+}
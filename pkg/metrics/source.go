@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/icinga/icinga-go-library/database"
+	kcache "k8s.io/client-go/tools/cache"
+)
+
+// Source synchronizes metrics of nodes and pods from a metrics backend to the database.
+type Source interface {
+	// Nodes synchronizes the metrics of all nodes known to the given informer.
+	Nodes(ctx context.Context, informer kcache.SharedIndexInformer) error
+	// Pods synchronizes the metrics of all pods known to any of the given informers,
+	// which may each be restricted to a single namespace.
+	Pods(ctx context.Context, informers ...kcache.SharedIndexInformer) error
+}
+
+// metricUpsertStmt returns database upsert statement to upsert metrics into the given table,
+// whose primary key consists of idColumn, timestamp, category and name.
+func metricUpsertStmt(db *database.DB, table, idColumn string) string {
+	var clause string
+	switch db.DriverName() {
+	case database.PostgreSQL:
+		clause = fmt.Sprintf(`ON CONFLICT ON CONSTRAINT pk_%s DO UPDATE SET value = EXCLUDED.value`, table)
+	default:
+		clause = `ON DUPLICATE KEY UPDATE value = VALUES(value)`
+	}
+
+	return fmt.Sprintf(
+		`INSERT INTO %[1]s (%[2]s, timestamp, category, name, value) VALUES (:%[2]s, :timestamp, :category, :name, :value) %[3]s`,
+		table,
+		idColumn,
+		clause,
+	)
+}
+
+// Assert interface compliance.
+var (
+	_ Source = (*PromMetricSync)(nil)
+	_ Source = (*MetricsServerSync)(nil)
+)
@@ -0,0 +1,79 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// alertmanagerSeverities maps the severities of events other than ok to the severity labels
+// commonly used with Alertmanager.
+var alertmanagerSeverities = map[string]string{
+	"crit":    "critical",
+	"err":     "error",
+	"warning": "warning",
+	"info":    "info",
+}
+
+// alert is an alert in the format of the Alertmanager API v2.
+type alert struct {
+	Labels       map[string]string `json:"labels"`
+	Annotations  map[string]string `json:"annotations"`
+	EndsAt       time.Time         `json:"endsAt"`
+	GeneratorURL string            `json:"generatorURL"`
+}
+
+// AlertmanagerSink sends events as alerts to the /api/v2/alerts endpoint of Alertmanager.
+// Events other than ok fire an alert labeled with their severity, and resolve the alerts of the resource
+// with other severities. Ok events resolve all alerts of the resource.
+type AlertmanagerSink struct {
+	config SinkConfig
+	client http.Client
+}
+
+// Name implements the Sink interface.
+func (a *AlertmanagerSink) Name() string {
+	return a.config.Name
+}
+
+// Send implements the Sink interface.
+func (a *AlertmanagerSink) Send(ctx context.Context, event Event) error {
+	annotations := map[string]string{"summary": event.Name, "description": event.Message}
+	for name, value := range event.ExtraTags {
+		annotations[name] = value
+	}
+
+	now := time.Now()
+	alerts := make([]alert, 0, len(alertmanagerSeverities))
+	for eventSeverity, severity := range alertmanagerSeverities {
+		labels := map[string]string{
+			"alertname": event.Kind,
+			"severity":  severity,
+		}
+		for name, value := range event.Tags {
+			labels[name] = value
+		}
+
+		endsAt := now
+		if eventSeverity == event.Severity {
+			endsAt = now.Add(a.config.ResolveTimeout)
+		}
+
+		alerts = append(alerts, alert{
+			Labels:       labels,
+			Annotations:  annotations,
+			EndsAt:       endsAt,
+			GeneratorURL: event.URL.String(),
+		})
+	}
+
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return errors.Wrap(err, "cannot encode alerts")
+	}
+
+	return do(ctx, a.client, a.config.url.JoinPath("api/v2/alerts").String(), body, a.config.Headers)
+}
@@ -0,0 +1,122 @@
+package notifications
+
+import (
+	"context"
+	"time"
+
+	"github.com/icinga/icinga-go-library/notifications/event"
+	"github.com/icinga/icinga-go-library/types"
+	"k8s.io/klog/v2"
+)
+
+// Annotations of resources that control how their events are sent. Annotations of a namespace
+// apply to all resources in the namespace that do not set the same annotations themselves.
+const (
+	// AnnotationNotifications suppresses the events of the resource other than ok if set to "disabled".
+	AnnotationNotifications = "icinga.com/notifications"
+	// AnnotationSeverityOverride replaces the severity of the events of the resource other than ok,
+	// e.g. with "warning".
+	AnnotationSeverityOverride = "icinga.com/severity-override"
+	// AnnotationOwnerTeam names the team responsible for the resource.
+	AnnotationOwnerTeam = "icinga.com/owner-team"
+	// AnnotationMaintenanceUntil suppresses the events of the resource other than ok until the given RFC 3339 time.
+	AnnotationMaintenanceUntil = "icinga.com/maintenance-until"
+)
+
+// annotationExtraTags maps the controlling annotations to the extra tags their values are added as.
+var annotationExtraTags = map[string]string{
+	AnnotationNotifications:    "notifications",
+	AnnotationSeverityOverride: "severity_override",
+	AnnotationOwnerTeam:        "owner_team",
+	AnnotationMaintenanceUntil: "maintenance_until",
+}
+
+// NamespaceAnnotations returns the annotations of the given namespace, or nil if the namespace is unknown.
+type NamespaceAnnotations func(namespace string) map[string]string
+
+// SetNamespaceAnnotations sets the function to look up the annotations of namespaces,
+// which act as defaults for the resources in the namespace. Must be called before streaming.
+func (c *Client) SetNamespaceAnnotations(fn NamespaceAnnotations) {
+	c.namespaceAnnotations = fn
+}
+
+// applyAnnotations adjusts the given event according to the controlling annotations of its resource and namespace
+// and returns the adjusted event, whether it is suppressed, and when the suppression ends, if it ends at all.
+func (c *Client) applyAnnotations(ev Event) (Event, bool, time.Time) {
+	annotations := make(map[string]string, len(annotationExtraTags))
+	if namespace := ev.Tags["namespace"]; namespace != "" && c.namespaceAnnotations != nil {
+		for name, value := range c.namespaceAnnotations(namespace) {
+			if _, ok := annotationExtraTags[name]; ok {
+				annotations[name] = value
+			}
+		}
+	}
+	for name, value := range ev.Annotations {
+		if _, ok := annotationExtraTags[name]; ok {
+			annotations[name] = value
+		}
+	}
+
+	if len(annotations) == 0 {
+		return ev, false, time.Time{}
+	}
+
+	extraTags := make(map[string]string, len(ev.ExtraTags)+len(annotations))
+	for name, value := range ev.ExtraTags {
+		extraTags[name] = value
+	}
+	for name, value := range annotations {
+		extraTags[annotationExtraTags[name]] = value
+	}
+	ev.ExtraTags = extraTags
+
+	if ev.Severity == "ok" {
+		return ev, false, time.Time{}
+	}
+
+	if override, ok := annotations[AnnotationSeverityOverride]; ok {
+		if severity, err := event.ParseSeverity(override); err != nil || severity == event.SeverityOK {
+			klog.Warningf("Ignoring invalid annotation %s=%q of %q", AnnotationSeverityOverride, override, ev.Name)
+		} else {
+			ev.Severity = override
+		}
+	}
+
+	if annotations[AnnotationNotifications] == "disabled" {
+		return ev, true, time.Time{}
+	}
+
+	if until, ok := annotations[AnnotationMaintenanceUntil]; ok {
+		t, err := time.Parse(time.RFC3339, until)
+		if err != nil {
+			klog.Warningf("Ignoring invalid annotation %s=%q of %q: %v", AnnotationMaintenanceUntil, until, ev.Name, err)
+		} else if time.Now().Before(t) {
+			return ev, true, t
+		}
+	}
+
+	return ev, false, time.Time{}
+}
+
+// recheckAt handles the last event of the given resource again at the given time, i.e. once its maintenance ends,
+// so that an incident is opened if the resource is still in a problem state.
+func (c *Client) recheckAt(ctx context.Context, id types.UUID, at time.Time) {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	if timer, ok := c.rechecks[id]; ok {
+		timer.Stop()
+	}
+
+	c.rechecks[id] = time.AfterFunc(time.Until(at), func() {
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.stateMu.Lock()
+		delete(c.rechecks, id)
+		c.stateMu.Unlock()
+
+		c.handleLast(ctx, id)
+	})
+}
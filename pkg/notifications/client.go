@@ -2,16 +2,19 @@ package notifications
 
 import (
 	"context"
-	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
+	"regexp"
+	"slices"
 	"sync"
+	"time"
 
 	"github.com/icinga/icinga-go-library/database"
 	"github.com/icinga/icinga-go-library/notifications/source"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/com"
+	"github.com/icinga/icinga-kubernetes/pkg/telemetry"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
 )
@@ -21,36 +24,84 @@ type Client struct {
 	rawClient http.Client
 	webUrl    *url.URL
 	db        *database.DB
+	// mu guards rulesInfo, the rules last received from Icinga Notifications.
 	mu        sync.Mutex
 	rulesInfo *source.RulesInfo
+	rules     ruleCache
+	owners    ownerCache
+	// sinks are the destinations events are sent to, including the client itself if Icinga Notifications is configured.
+	sinks []Sink
+
+	// last holds the last event streamed per resource, so that its incident can be resolved on deletion.
+	last map[types.UUID]Event
+	// states holds the last state sent per resource, so that events are only sent on severity changes.
+	states         map[types.UUID]State
+	stateMu        sync.Mutex
+	resendInterval time.Duration
+
+	// pending holds the resources with events in the outbox, which failed to be sent and are retried by DrainOutbox.
+	pending      map[types.UUID]struct{}
+	outboxMu     sync.Mutex
+	outboxSignal chan struct{}
+	// outboxVersion is the version of the event last stored in the outbox, which identifies it for dequeue.
+	outboxVersion uint64
+
+	// locks serializes the handling of events per resource, which happens concurrently from Stream,
+	// StreamDeletes, Resend and the rechecks after maintenances and downtimes.
+	locks uuidLocks
+
+	namespaceAnnotations NamespaceAnnotations
+	downtimes            Downtimes
+	// rechecks holds the timers per resource that handle its last event again once its maintenance ends.
+	rechecks map[types.UUID]*time.Timer
+
+	correlation string
+	labels      *regexp.Regexp
+	annotations *regexp.Regexp
+	// causes holds the root cause per failing resource whose problem is caused by a failing dependency.
+	causes map[types.UUID]types.UUID
 }
 
 func NewClient(name string, config Config, db *database.DB) (*Client, error) {
-	baseUrl, err := url.Parse(config.Url)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to parse url")
-	}
-
 	webUrl, err := url.Parse(config.KubernetesWebUrl)
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to parse web url")
 	}
 
-	client, err := source.NewClient(source.Config{
-		Url:      baseUrl.String(),
-		Username: config.Username,
-		Password: config.Password,
-	}, name)
-	if err != nil {
-		return nil, errors.Wrap(err, "unable to create notifications client")
-	}
-
-	return &Client{
-		client:    client,
+	c := &Client{
 		webUrl:    webUrl,
 		rulesInfo: &source.RulesInfo{},
 		db:        db,
-		rawClient: http.Client{
+		last:      make(map[types.UUID]Event),
+		states:    make(map[types.UUID]State),
+		pending:   make(map[types.UUID]struct{}),
+		rechecks:  make(map[types.UUID]*time.Timer),
+		causes:    make(map[types.UUID]types.UUID),
+
+		outboxSignal: make(chan struct{}, 1),
+
+		resendInterval: config.ResendInterval,
+		correlation:    config.Correlation,
+		labels:         compilePatterns(config.Labels),
+		annotations:    compilePatterns(config.Annotations),
+	}
+
+	if config.Url != "" {
+		baseUrl, err := url.Parse(config.Url)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to parse url")
+		}
+
+		c.client, err = source.NewClient(source.Config{
+			Url:      baseUrl.String(),
+			Username: config.Username,
+			Password: config.Password,
+		}, name)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to create notifications client")
+		}
+
+		c.rawClient = http.Client{
 			Transport: &com.BasicAuthTransport{
 				RoundTripper: &ScopeTransport{
 					RoundTripper: http.DefaultTransport,
@@ -60,53 +111,115 @@ func NewClient(name string, config Config, db *database.DB) (*Client, error) {
 				Username: config.Username,
 				Password: config.Password,
 			},
-		},
-	}, nil
+		}
+
+		c.sinks = append(c.sinks, c)
+	}
+
+	for _, sink := range config.Sinks {
+		c.sinks = append(c.sinks, NewSink(sink))
+	}
+
+	return c, nil
+}
+
+// clientSinkName is the name of the client itself as a sink.
+const clientSinkName = "Icinga Notifications"
+
+// Name implements the Sink interface.
+func (c *Client) Name() string {
+	return clientSinkName
+}
+
+// Send implements the Sink interface by submitting the given event to Icinga Notifications.
+func (c *Client) Send(ctx context.Context, event Event) error {
+	return c.ProcessEvent(ctx, event)
+}
+
+// send enriches the given event and sends it to the sinks with the given names, or to all sinks if names is nil.
+// If any of them fails, it returns the names of the failed sinks, for which the event is to be retried, and an error.
+func (c *Client) send(ctx context.Context, event Event, names []string) ([]string, error) {
+	event = c.enrich(ctx, event)
+	event.URL = c.webUrl.ResolveReference(event.URL)
+
+	var failed []string
+	var err error
+	for _, sink := range c.sinks {
+		if names != nil && !slices.Contains(names, sink.Name()) {
+			continue
+		}
+
+		if sendErr := sink.Send(ctx, event); sendErr != nil {
+			if err != nil {
+				klog.Error(err)
+			}
+
+			failed = append(failed, sink.Name())
+			err = errors.Wrapf(sendErr, "cannot send event to %s", sink.Name())
+		}
+	}
+
+	return failed, err
+}
+
+// sinkNames returns the names of all sinks.
+func (c *Client) sinkNames() []string {
+	names := make([]string, 0, len(c.sinks))
+	for _, sink := range c.sinks {
+		names = append(names, sink.Name())
+	}
+
+	return names
 }
 
 func (c *Client) ProcessEvent(ctx context.Context, event Event) error {
 	event.URL = c.webUrl.ResolveReference(event.URL)
 	ev := event.Carry()
 
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
 	for try := 0; try < 3; try++ {
-		eventRuleIds, err := c.evaluateRulesForObject(
-			ctx,
-			event.Kind,
-			event.Uuid,
-			event.ClusterUuid)
+		c.mu.Lock()
+		rulesInfo := c.rulesInfo
+		c.mu.Unlock()
+
+		eventRuleIds, err := c.evaluateRules(ctx, rulesInfo, event)
 		if err != nil {
 			klog.Errorf("Cannot evaluate rules for event, assuming no rule matched: %v", err)
 			eventRuleIds = []string{}
 		}
 
-		ev.RulesVersion = c.rulesInfo.Version
+		ev.RulesVersion = rulesInfo.Version
 		ev.RuleIds = eventRuleIds
 
 		newEventRules, err := c.client.ProcessEvent(ctx, ev)
 		if errors.Is(err, source.ErrRulesOutdated) {
 			klog.Infof("Received a rule update from Icinga Notifications, resubmitting event (old_rules_version: %q, new_rules_version: %q)",
-				c.rulesInfo.Version,
+				rulesInfo.Version,
 				newEventRules.Version)
 
+			c.mu.Lock()
 			c.rulesInfo = newEventRules
+			c.mu.Unlock()
 
 			continue
 		} else if err != nil {
-			return errors.Wrapf(err, "cannot submit event to Icinga Notifications (matched_rules: %v, rules_version: %q)", eventRuleIds, c.rulesInfo.Version)
+			telemetry.NotificationEventsFailed.WithLabelValues(event.Kind).Inc()
+
+			return errors.Wrapf(err, "cannot submit event to Icinga Notifications (matched_rules: %v, rules_version: %q)", eventRuleIds, rulesInfo.Version)
 		}
 
+		telemetry.NotificationEvents.WithLabelValues(event.Kind).Inc()
 		klog.V(2).Infof("Successfully submitted event to Icinga Notifications (matched_rules: %v)", eventRuleIds)
 
 		return nil
 	}
 
+	telemetry.NotificationEventsFailed.WithLabelValues(event.Kind).Inc()
+
 	return errors.New("Received three rule updates from Icinga Notifications in a row")
 }
 
-// Stream consumes the items from the given `entities` chan and triggers a notifications event for each of them.
+// Stream consumes the items from the given `entities` chan and triggers a notifications event for each of them
+// whose severity differs from the last one sent for the same resource.
 func (c *Client) Stream(ctx context.Context, entities <-chan any) error {
 	for {
 		select {
@@ -121,90 +234,216 @@ func (c *Client) Stream(ctx context.Context, entities <-chan any) error {
 				continue
 			}
 
-			if err := c.ProcessEvent(ctx, event); err != nil {
-				klog.Errorf("Cannot process event: %v", err)
-			}
+			// SQL rules may depend on any property of the resource, and its owners may have changed.
+			c.rules.forget(event.Uuid)
+			c.owners.invalidate(event.Uuid)
+
+			unlock := c.locks.lock(event.Uuid)
+			c.stateMu.Lock()
+			c.last[event.Uuid] = event
+			c.stateMu.Unlock()
+
+			c.handle(ctx, event)
+			unlock()
 		case <-ctx.Done():
 			return ctx.Err()
 		}
 	}
 }
 
-func (c *Client) Incidents(ctx context.Context) (io.ReadCloser, error) {
-	r, err := http.NewRequestWithContext(ctx, "GET", "incidents", nil)
-	if err != nil {
-		return nil, err
+// handleLast handles the last event streamed for the given resource again, if any.
+func (c *Client) handleLast(ctx context.Context, id types.UUID) {
+	defer c.locks.lock(id)()
+
+	c.stateMu.Lock()
+	event, ok := c.last[id]
+	c.stateMu.Unlock()
+
+	if ok {
+		c.handle(ctx, event)
 	}
+}
 
-	res, err := c.rawClient.Do(r)
-	if err != nil {
-		return nil, err
+// handle sends the given event unless it is suppressed by the annotations of its resource,
+// its severity has not changed, or it is caused by a failing dependency and correlation suppresses it.
+// The lock of the resource must be held, see uuidLocks.
+func (c *Client) handle(ctx context.Context, event Event) {
+	event, suppressed, until := c.applyAnnotations(event)
+	if !until.IsZero() {
+		c.recheckAt(ctx, event.Uuid, until)
 	}
 
-	return res.Body, nil
-}
+	if suppressed {
+		klog.V(2).Infof("Suppressing event of %q as configured by its annotations", event.Name)
 
-func (c *Client) evaluateRulesForObject(ctx context.Context, kind string, uuid, clusterUuid types.UUID) ([]string, error) {
-	const expectedRuleVersion = 1
+		return
+	}
 
-	outRuleIds := make([]string, 0, len(c.rulesInfo.Rules))
+	if end, ok := c.inDowntime(event); ok {
+		klog.V(2).Infof("Holding back event of %q until its downtime ends at %s", event.Name, end)
+		c.recheckAt(ctx, event.Uuid, end)
 
-	for id, filterExpr := range c.rulesInfo.Rules {
-		if filterExpr == "" {
-			// TODO(el): Why?
-			outRuleIds = append(outRuleIds, id)
-			continue
-		}
+		return
+	}
 
-		var r rule
-		if err := json.Unmarshal([]byte(filterExpr), &r); err != nil {
-			return nil, errors.Wrap(err, "cannot decode rule filter expression as JSON into struct")
-		}
-		if version := r.Version; version != expectedRuleVersion {
-			return nil, errors.Errorf("decoded rule filter expression .Version is %d, %d expected", version, expectedRuleVersion)
-		}
+	if c.enqueueIfPending(ctx, event) {
+		return
+	}
 
-		if r.Kind != kind {
-			continue
-		}
+	if !c.changed(event) {
+		return
+	}
 
-		args := make([]any, 0, len(r.Args))
-		for _, param := range r.Args {
-			switch param {
-			case ":uuid":
-				args = append(args, uuid)
-			case ":cluster_uuid":
-				args = append(args, clusterUuid)
-			default:
-				args = append(args, param)
-			}
-		}
+	event, caused := c.correlate(ctx, event)
+	if caused && c.correlation == CorrelationSuppress {
+		klog.V(2).Infof("Holding back event of %q while its cause %q is failing", event.Name, event.ExtraTags["caused_by"])
 
-		matches, err := func() (bool, error) {
-			rows, err := c.db.QueryContext(ctx, c.db.Rebind(r.Query), args...)
-			if err != nil {
-				return false, err
+		return
+	}
+
+	if failed, err := c.send(ctx, event, nil); err != nil {
+		klog.Errorf("Cannot process event, retrying later: %v", err)
+		c.enqueue(ctx, event, failed)
+
+		return
+	}
+
+	c.remember(ctx, event)
+}
+
+// Downtimes reports whether a resource is covered by a downtime and when the downtime ends.
+type Downtimes interface {
+	Active(id types.UUID, namespace string, labels map[string]string) (time.Time, bool)
+}
+
+// SetDowntimes sets the downtimes during which events other than ok are held back
+// until the downtimes end. Must be called before streaming.
+func (c *Client) SetDowntimes(downtimes Downtimes) {
+	c.downtimes = downtimes
+}
+
+// inDowntime returns whether the given event is to be held back due to a downtime and when the downtime ends.
+func (c *Client) inDowntime(event Event) (time.Time, bool) {
+	if c.downtimes == nil || event.Severity == "ok" {
+		return time.Time{}, false
+	}
+
+	return c.downtimes.Active(event.Uuid, event.Tags["namespace"], event.Labels)
+}
+
+// StreamDeletes consumes the UUIDs of deleted resources from the given `uuids` chan and
+// immediately resolves their incidents by triggering an ok event for each resource previously streamed via Stream.
+// Resources not streamed before are ignored, as they are resolved by the periodic sweep of orphaned incidents.
+func (c *Client) StreamDeletes(ctx context.Context, uuids <-chan any) error {
+	for {
+		select {
+		case id, more := <-uuids:
+			if !more {
+				return nil
 			}
-			defer func() { _ = rows.Close() }()
 
-			return rows.Next(), nil
-		}()
-		if err != nil {
-			return nil, errors.Wrapf(err, "cannot fetch rule %q from %q", id, filterExpr)
-		} else if !matches {
-			continue
+			c.resolveDeleted(ctx, id.(types.UUID))
+		case <-ctx.Done():
+			return ctx.Err()
 		}
-		outRuleIds = append(outRuleIds, id)
+	}
+}
+
+// resolveDeleted resolves the incident of the given deleted resource.
+func (c *Client) resolveDeleted(ctx context.Context, id types.UUID) {
+	defer c.locks.lock(id)()
+
+	c.stateMu.Lock()
+	event, ok := c.last[id]
+	state, sent := c.states[id]
+	delete(c.last, id)
+	c.stateMu.Unlock()
+
+	// The same UUID may be received multiple times, e.g. once per deleted relation.
+	if !ok {
+		return
+	}
+
+	event.Severity = "ok"
+	event.Message = "Automatically resolving the incident because the resource has been deleted."
+	event.deleted = true
+	event, _, _ = c.applyAnnotations(event)
+
+	if c.enqueueIfPending(ctx, event) {
+		return
 	}
 
-	return outRuleIds, nil
+	if !sent || state.Severity == "ok" {
+		// There is no incident to resolve.
+		c.Forget(ctx, event.Uuid)
+
+		return
+	}
+
+	klog.V(2).Infof("Resolving incident of deleted resource: %q", event.Name)
+
+	if failed, err := c.send(ctx, event, nil); err != nil {
+		klog.Errorf("Cannot resolve incident of deleted resource, retrying later: %v", err)
+		c.enqueue(ctx, event, failed)
+
+		return
+	}
+
+	c.Forget(ctx, event.Uuid)
+}
+
+// uuidLocks provides a mutex per resource UUID, which is removed once no longer in use.
+type uuidLocks struct {
+	mu    sync.Mutex
+	locks map[types.UUID]*uuidLock
+}
+
+type uuidLock struct {
+	sync.Mutex
+	refs int
+}
+
+// lock locks the mutex of the given UUID and returns the function to unlock it.
+func (l *uuidLocks) lock(id types.UUID) func() {
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[types.UUID]*uuidLock)
+	}
+
+	lock, ok := l.locks[id]
+	if !ok {
+		lock = &uuidLock{}
+		l.locks[id] = lock
+	}
+	lock.refs++
+	l.mu.Unlock()
+
+	lock.Lock()
+
+	return func() {
+		lock.Unlock()
+
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if lock.refs--; lock.refs == 0 {
+			delete(l.locks, id)
+		}
+	}
 }
 
-type rule struct {
-	Version int    `json:"version"`
-	Kind    string `json:"kind"`
-	Query   string `json:"query"`
-	Args    []any  `json:"args"`
+func (c *Client) Incidents(ctx context.Context) (io.ReadCloser, error) {
+	r, err := http.NewRequestWithContext(ctx, "GET", "incidents", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.rawClient.Do(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return res.Body, nil
 }
 
 type ScopeTransport struct {
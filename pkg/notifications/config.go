@@ -4,6 +4,7 @@ import (
 	"github.com/pkg/errors"
 	"net/url"
 	"regexp"
+	"time"
 )
 
 type Config struct {
@@ -12,6 +13,26 @@ type Config struct {
 	Username         string `yaml:"username" env:"USERNAME"`
 	Password         string `yaml:"password" env:"PASSWORD"`
 	KubernetesWebUrl string `yaml:"kubernetes_web_url" env:"KUBERNETES_WEB_URL" default:"http://localhost/icingaweb2/kubernetes"`
+	// Events are only sent if the severity of a resource changes. If ResendInterval is positive,
+	// the last event of a resource is sent again if it has not been sent for that long.
+	ResendInterval time.Duration `yaml:"resend_interval" env:"RESEND_INTERVAL"`
+	// Containers defines whether the containers of pods are forwarded individually in addition to their pods.
+	Containers bool `yaml:"containers" env:"CONTAINERS"`
+	// Correlation defines how events of resources caused by failing resources they depend on,
+	// e.g. the pods of a node that is not ready, are handled. See the Correlation* constants.
+	Correlation string `yaml:"correlation" env:"CORRELATION" default:"tag"`
+	// Labels and Annotations are allow-lists of the labels and annotations of resources that are added to their
+	// events as extra tags prefixed with "label:" and "annotation:". Entries may contain the wildcards * and ?,
+	// where * also matches /, e.g. app.kubernetes.io/*. See compilePatterns.
+	Labels      []string `yaml:"labels" env:"LABELS"`
+	Annotations []string `yaml:"annotations" env:"ANNOTATIONS"`
+	// Sinks defines further destinations events are sent to in addition to or instead of Icinga Notifications.
+	Sinks []SinkConfig `yaml:"sinks"`
+}
+
+// Enabled returns whether events are sent anywhere, i.e. to Icinga Notifications or any sink.
+func (c *Config) Enabled() bool {
+	return c.Url != "" || len(c.Sinks) > 0
 }
 
 // Validate checks constraints in the supplied configuration and returns an error if they are violated.
@@ -38,5 +59,36 @@ func (c *Config) Validate() error {
 		return errors.Wrap(err, "'kubernetes_web_url' invalid")
 	}
 
+	if c.ResendInterval < 0 {
+		return errors.New("'resend_interval' must not be negative")
+	}
+
+	switch c.Correlation {
+	case CorrelationDisabled, CorrelationTag, CorrelationSuppress:
+	default:
+		return errors.Errorf("'correlation' must be one of %q, %q or %q", CorrelationDisabled, CorrelationTag, CorrelationSuppress)
+	}
+
+	// Sink names identify the sinks events are still pending for in the outbox.
+	names := map[string]struct{}{clientSinkName: {}}
+	for i := range c.Sinks {
+		if err := c.Sinks[i].Validate(); err != nil {
+			return errors.Wrapf(err, "invalid sink %d", i)
+		}
+
+		if _, ok := names[c.Sinks[i].Name]; ok {
+			return errors.Errorf("invalid sink %d: duplicate name %q", i, c.Sinks[i].Name)
+		}
+		names[c.Sinks[i].Name] = struct{}{}
+
+		// Resends are checked once per interval, so an event may be resent only after almost twice the interval.
+		if c.Sinks[i].Type == SinkTypeAlertmanager &&
+			(c.ResendInterval <= 0 || 2*c.ResendInterval > c.Sinks[i].ResolveTimeout) {
+			return errors.Errorf(
+				"invalid sink %d: 'resend_interval' must be set to at most half of 'resolve_timeout' (%s) "+
+					"as Alertmanager resolves alerts that are not sent again", i, c.Sinks[i].ResolveTimeout)
+		}
+	}
+
 	return nil
 }
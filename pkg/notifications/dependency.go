@@ -0,0 +1,198 @@
+package notifications
+
+import (
+	"context"
+	"strings"
+
+	"github.com/icinga/icinga-go-library/types"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// Correlation modes, see Config.Correlation.
+const (
+	CorrelationDisabled = "disabled"
+	CorrelationTag      = "tag"
+	CorrelationSuppress = "suppress"
+)
+
+// maxDependencyDepth limits how far dependencies are followed to find the root cause of an event,
+// e.g. deployment -> replica set -> pod -> node.
+const maxDependencyDepth = 5
+
+// rootCauseKinds are the kinds of resources that can be the root cause of problems of resources depending on them.
+// Failing resources of other kinds only pass on the problems of these, so that e.g. a pod failing on its own
+// is not considered the root cause of the problems of its replica set.
+var rootCauseKinds = map[string]bool{
+	"node":              true,
+	"persistent_volume": true,
+}
+
+// dependencyQueries map the kinds of resources to queries selecting the UUIDs of the resources they depend on,
+// i.e. whose problems cause problems of them. Each placeholder is bound to the UUID of the resource.
+var dependencyQueries = map[string]string{
+	"pod": `SELECT node.uuid FROM pod INNER JOIN node ` +
+		`ON node.cluster_uuid = pod.cluster_uuid AND node.name = pod.node_name WHERE pod.uuid = ? ` +
+		`UNION SELECT pvc.uuid FROM pod_pvc INNER JOIN pod ON pod.uuid = pod_pvc.pod_uuid INNER JOIN pvc ` +
+		`ON pvc.cluster_uuid = pod.cluster_uuid AND pvc.namespace = pod.namespace AND pvc.name = pod_pvc.claim_name ` +
+		`WHERE pod.uuid = ?`,
+	"pvc": `SELECT persistent_volume.uuid FROM pvc INNER JOIN persistent_volume ` +
+		`ON persistent_volume.cluster_uuid = pvc.cluster_uuid AND persistent_volume.name = pvc.volume_name ` +
+		`WHERE pvc.uuid = ?`,
+	"container": `SELECT node.uuid FROM container INNER JOIN pod ON pod.uuid = container.pod_uuid INNER JOIN node ` +
+		`ON node.cluster_uuid = pod.cluster_uuid AND node.name = pod.node_name WHERE container.uuid = ?`,
+	"init_container": `SELECT node.uuid FROM init_container INNER JOIN pod ON pod.uuid = init_container.pod_uuid ` +
+		`INNER JOIN node ON node.cluster_uuid = pod.cluster_uuid AND node.name = pod.node_name ` +
+		`WHERE init_container.uuid = ?`,
+	"sidecar_container": `SELECT node.uuid FROM sidecar_container INNER JOIN pod ON pod.uuid = sidecar_container.pod_uuid ` +
+		`INNER JOIN node ON node.cluster_uuid = pod.cluster_uuid AND node.name = pod.node_name ` +
+		`WHERE sidecar_container.uuid = ?`,
+	"daemon_set":   `SELECT pod_uuid FROM pod_owner WHERE owner_uuid = ?`,
+	"job":          `SELECT pod_uuid FROM pod_owner WHERE owner_uuid = ?`,
+	"replica_set":  `SELECT pod_uuid FROM pod_owner WHERE owner_uuid = ?`,
+	"stateful_set": `SELECT pod_uuid FROM pod_owner WHERE owner_uuid = ?`,
+	"cron_job":     `SELECT job_uuid FROM job_owner WHERE owner_uuid = ?`,
+	"deployment":   `SELECT replica_set_uuid FROM replica_set_owner WHERE owner_uuid = ?`,
+	"service":      `SELECT pod_uuid FROM service_pod WHERE service_uuid = ?`,
+}
+
+// correlate looks for a failing resource the resource of the given event depends on, e.g. the node of a pod.
+// If there is one, the root cause is added to the event as extra tags and the event is returned as caused.
+// Caused events are held back in CorrelationSuppress mode until the incident of the root cause is resolved.
+func (c *Client) correlate(ctx context.Context, event Event) (Event, bool) {
+	if c.correlation == CorrelationDisabled || event.Severity == "ok" {
+		c.stateMu.Lock()
+		delete(c.causes, event.Uuid)
+		c.stateMu.Unlock()
+
+		return event, false
+	}
+
+	cause, err := c.rootCause(ctx, event.Kind, event.Uuid, 0)
+	if err != nil {
+		klog.Errorf("Cannot correlate event of %q, assuming it has no cause: %v", event.Name, err)
+	}
+
+	c.stateMu.Lock()
+	if cause == nil {
+		delete(c.causes, event.Uuid)
+	} else {
+		c.causes[event.Uuid] = *cause
+	}
+	causeName := c.describe(cause)
+	c.stateMu.Unlock()
+
+	if cause == nil {
+		return event, false
+	}
+
+	extraTags := make(map[string]string, len(event.ExtraTags)+2)
+	for name, value := range event.ExtraTags {
+		extraTags[name] = value
+	}
+	extraTags["caused_by"] = causeName
+	extraTags["caused_by_uuid"] = cause.String()
+	event.ExtraTags = extraTags
+
+	return event, true
+}
+
+// rootCause returns the failing resource of one of the rootCauseKinds furthest up the dependencies
+// of the given resource, or nil if there is none.
+func (c *Client) rootCause(ctx context.Context, kind string, id types.UUID, depth int) (*types.UUID, error) {
+	query, ok := dependencyQueries[kind]
+	if !ok || depth >= maxDependencyDepth {
+		return nil, nil
+	}
+
+	args := make([]interface{}, strings.Count(query, "?"))
+	for i := range args {
+		args[i] = id
+	}
+
+	var dependencies []types.UUID
+	if err := c.db.SelectContext(ctx, &dependencies, c.db.Rebind(query), args...); err != nil {
+		return nil, errors.Wrapf(err, "cannot fetch dependencies of %s %s", kind, id)
+	}
+
+	for _, dependency := range dependencies {
+		c.stateMu.Lock()
+		state, sent := c.states[dependency]
+		cause, caused := c.causes[dependency]
+		c.stateMu.Unlock()
+
+		if caused {
+			// The dependency is failing itself, but its root cause has already been determined.
+			return &cause, nil
+		}
+
+		if !sent || state.Severity == "ok" {
+			continue
+		}
+
+		root, err := c.rootCause(ctx, state.Kind, dependency, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if root != nil {
+			return root, nil
+		}
+
+		if rootCauseKinds[state.Kind] {
+			return &dependency, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// describe returns the kind and name of the given resource for the caused_by tag.
+// c.stateMu must be held.
+func (c *Client) describe(id *types.UUID) string {
+	if id == nil {
+		return ""
+	}
+
+	if event, ok := c.last[*id]; ok {
+		return event.Kind + ":" + strings.TrimPrefix(event.Name, "/")
+	}
+
+	if state, ok := c.states[*id]; ok {
+		return state.Kind + ":" + id.String()
+	}
+
+	return id.String()
+}
+
+// release handles the last events of the resources caused by the given resource again after its incident
+// has been resolved, so that incidents are opened for those that are still failing on their own.
+func (c *Client) release(ctx context.Context, id types.UUID) {
+	var ids []types.UUID
+
+	c.stateMu.Lock()
+	for caused, cause := range c.causes {
+		if cause != id {
+			continue
+		}
+
+		delete(c.causes, caused)
+		ids = append(ids, caused)
+	}
+	c.stateMu.Unlock()
+
+	if len(ids) == 0 {
+		return
+	}
+
+	klog.V(2).Infof("Re-evaluating %d events caused by resolved resource %s", len(ids), id)
+
+	go func() {
+		for _, caused := range ids {
+			if ctx.Err() != nil {
+				return
+			}
+
+			c.handleLast(ctx, caused)
+		}
+	}()
+}
@@ -0,0 +1,204 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/icinga/icinga-go-library/types"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// maxOwnerDepth limits how far owner references are followed to find the top-level owner of a resource,
+// e.g. pod -> replica set -> deployment.
+const maxOwnerDepth = 5
+
+// ownerKinds are the kinds of resources whose owners are stored in a table <kind>_owner keyed by <kind>_uuid.
+var ownerKinds = map[string]struct{}{
+	"daemon_set":   {},
+	"deployment":   {},
+	"job":          {},
+	"pod":          {},
+	"replica_set":  {},
+	"stateful_set": {},
+}
+
+// owner is a controlling owner of a resource.
+type owner struct {
+	OwnerUuid types.UUID
+	Kind      string
+	Name      string
+}
+
+// enrich adds the allowed labels and annotations of the resource and its top-level owner
+// to the extra tags of the given event.
+func (c *Client) enrich(ctx context.Context, event Event) Event {
+	extraTags := make(map[string]string, len(event.ExtraTags))
+	for name, value := range event.ExtraTags {
+		extraTags[name] = value
+	}
+
+	for name, value := range event.Labels {
+		if allowed(c.labels, name) {
+			extraTags["label:"+name] = value
+		}
+	}
+
+	for name, value := range event.Annotations {
+		if allowed(c.annotations, name) {
+			extraTags["annotation:"+name] = value
+		}
+	}
+
+	top, err := c.topLevelOwner(ctx, event.Kind, event.Uuid)
+	if err != nil {
+		klog.Errorf("Cannot resolve owner of %q: %v", event.Name, err)
+	} else if top != nil {
+		extraTags["owner_kind"] = top.Kind
+		extraTags["owner_name"] = top.Name
+	}
+
+	if len(extraTags) > 0 {
+		event.ExtraTags = extraTags
+	}
+
+	return event
+}
+
+// ownerCache caches the top-level owner per resource. Entries are invalidated if the resource
+// or any of the owners in between is updated, see invalidate.
+type ownerCache struct {
+	mu     sync.Mutex
+	owners map[types.UUID]*owner
+	// via holds the owners via which the cached top-level owner of the given resource was resolved.
+	via map[types.UUID][]types.UUID
+	// dependents holds the resources whose cached top-level owner was resolved via the given owner.
+	dependents map[types.UUID]map[types.UUID]struct{}
+}
+
+// get returns the cached top-level owner of the given resource, which may be nil if it is not owned.
+func (oc *ownerCache) get(id types.UUID) (*owner, bool) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	top, ok := oc.owners[id]
+
+	return top, ok
+}
+
+// store caches the top-level owner of the given resource resolved via the given owners.
+func (oc *ownerCache) store(id types.UUID, top *owner, via []types.UUID) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	if oc.owners == nil {
+		oc.owners = make(map[types.UUID]*owner)
+		oc.via = make(map[types.UUID][]types.UUID)
+		oc.dependents = make(map[types.UUID]map[types.UUID]struct{})
+	}
+
+	oc.forget(id)
+
+	oc.owners[id] = top
+	if len(via) > 0 {
+		oc.via[id] = via
+	}
+	for _, o := range via {
+		if oc.dependents[o] == nil {
+			oc.dependents[o] = make(map[types.UUID]struct{})
+		}
+		oc.dependents[o][id] = struct{}{}
+	}
+}
+
+// invalidate discards the cached top-level owner of the given resource and of the resources resolved via it.
+func (oc *ownerCache) invalidate(id types.UUID) {
+	oc.mu.Lock()
+	defer oc.mu.Unlock()
+
+	oc.forget(id)
+	for dependent := range oc.dependents[id] {
+		oc.forget(dependent)
+	}
+	delete(oc.dependents, id)
+}
+
+// forget discards the cached top-level owner of the given resource and removes it from the dependents
+// of the owners it was resolved via. oc.mu must be held.
+func (oc *ownerCache) forget(id types.UUID) {
+	delete(oc.owners, id)
+
+	for _, o := range oc.via[id] {
+		delete(oc.dependents[o], id)
+		if len(oc.dependents[o]) == 0 {
+			delete(oc.dependents, o)
+		}
+	}
+	delete(oc.via, id)
+}
+
+// topLevelOwner follows the controlling owners of the given resource up to the one without owner
+// and returns it, or nil if the resource is not owned. Results are cached, see ownerCache.
+func (c *Client) topLevelOwner(ctx context.Context, kind string, id types.UUID) (*owner, error) {
+	if top, ok := c.owners.get(id); ok {
+		return top, nil
+	}
+
+	var top *owner
+	var via []types.UUID
+	resource := id
+
+	for depth := 0; depth < maxOwnerDepth; depth++ {
+		if _, ok := ownerKinds[kind]; !ok {
+			break
+		}
+
+		var o owner
+		err := c.db.GetContext(ctx, &o, c.db.Rebind(fmt.Sprintf(
+			`SELECT owner_uuid, kind, name FROM %[1]s_owner WHERE %[1]s_uuid = ? AND controller = ?`,
+			kind,
+		)), id, types.Bool{Bool: true, Valid: true})
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot fetch owner of %s %s", kind, id)
+		}
+
+		top = &o
+		via = append(via, o.OwnerUuid)
+		kind, id = o.Kind, o.OwnerUuid
+	}
+
+	c.owners.store(resource, top, via)
+
+	return top, nil
+}
+
+// compilePatterns compiles the given wildcard patterns into a regular expression matching any of them.
+// Unlike with path.Match, * matches any sequence of characters including /, e.g. app.kubernetes.io/* matches
+// app.kubernetes.io/part-of/frontend as well. ? matches any single character. Returns nil if there are no patterns.
+func compilePatterns(patterns []string) *regexp.Regexp {
+	if len(patterns) == 0 {
+		return nil
+	}
+
+	alternatives := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		quoted := regexp.QuoteMeta(pattern)
+		quoted = strings.ReplaceAll(quoted, `\*`, `.*`)
+		quoted = strings.ReplaceAll(quoted, `\?`, `.`)
+		alternatives = append(alternatives, quoted)
+	}
+
+	return regexp.MustCompile(`^(?:` + strings.Join(alternatives, "|") + `)$`)
+}
+
+// allowed returns whether the given label or annotation name matches the given compiled patterns.
+func allowed(patterns *regexp.Regexp, name string) bool {
+	return patterns != nil && patterns.MatchString(name)
+}
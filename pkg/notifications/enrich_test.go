@@ -0,0 +1,74 @@
+package notifications
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/icinga/icinga-go-library/types"
+)
+
+func TestAllowed(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		label    string
+		want     bool
+	}{
+		{name: "none", patterns: nil, label: "app", want: false},
+		{name: "exact", patterns: []string{"team"}, label: "team", want: true},
+		{name: "exact-mismatch", patterns: []string{"team"}, label: "teams", want: false},
+		{name: "prefix", patterns: []string{"app.kubernetes.io/*"}, label: "app.kubernetes.io/name", want: true},
+		{name: "slash", patterns: []string{"app.kubernetes.io/*"}, label: "app.kubernetes.io/part-of/web", want: true},
+		{name: "dot-is-literal", patterns: []string{"app.kubernetes.io/*"}, label: "appXkubernetes.io/name", want: false},
+		{name: "question-mark", patterns: []string{"tier?"}, label: "tier1", want: true},
+		{name: "question-mark-mismatch", patterns: []string{"tier?"}, label: "tier", want: false},
+		{name: "any-of", patterns: []string{"team", "owner/*"}, label: "owner/email", want: true},
+		{name: "anchored", patterns: []string{"team"}, label: "my-team", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := allowed(compilePatterns(tt.patterns), tt.label); got != tt.want {
+				t.Errorf("allowed(%q, %q) = %t, want %t", tt.patterns, tt.label, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOwnerCache(t *testing.T) {
+	newUuid := func() types.UUID { return types.UUID{UUID: uuid.New()} }
+	pod, replicaSet, deployment := newUuid(), newUuid(), newUuid()
+	top := &owner{Kind: "deployment", Name: "web"}
+
+	var oc ownerCache
+
+	// Pods are invalidated and resolved again on every update.
+	for i := 0; i < 3; i++ {
+		oc.invalidate(pod)
+		oc.store(pod, top, []types.UUID{replicaSet, deployment})
+	}
+
+	for _, o := range []types.UUID{replicaSet, deployment} {
+		if n := len(oc.dependents[o]); n != 1 {
+			t.Errorf("owner has %d dependents, want 1", n)
+		}
+	}
+
+	if got, ok := oc.get(pod); !ok || got != top {
+		t.Errorf("get() = %v, %t, want %v, true", got, ok, top)
+	}
+
+	oc.invalidate(deployment)
+
+	if _, ok := oc.get(pod); ok {
+		t.Error("top-level owner still cached after invalidating an owner in between")
+	}
+
+	oc.store(pod, top, []types.UUID{replicaSet, deployment})
+	oc.invalidate(pod)
+
+	if len(oc.owners) != 0 || len(oc.via) != 0 || len(oc.dependents) != 0 {
+		t.Errorf("cache not empty after invalidating all resources: %d owners, %d via, %d dependents",
+			len(oc.owners), len(oc.via), len(oc.dependents))
+	}
+}
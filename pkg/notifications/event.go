@@ -18,6 +18,15 @@ type Event struct {
 	URL         *url.URL
 	Tags        map[string]string
 	ExtraTags   map[string]string
+	// Labels of the resource, which are matched against downtimes. They are not sent themselves.
+	Labels map[string]string
+	// Annotations of the resource, which control how the event is sent, see applyAnnotations.
+	// They are not sent themselves.
+	Annotations map[string]string
+
+	// deleted marks an event that resolves the incident of a deleted resource,
+	// whose state is forgotten once the event has been sent.
+	deleted bool
 }
 
 func (e Event) MarshalJSON() ([]byte, error) {
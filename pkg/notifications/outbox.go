@@ -0,0 +1,306 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/icinga/icinga-go-library/backoff"
+	"github.com/icinga/icinga-go-library/types"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// outboxBatchSize is the maximum number of events fetched from the outbox at once.
+const outboxBatchSize = 100
+
+// outboxEvent is an event that failed to be sent to Icinga Notifications and is retried later.
+// There is at most one event per resource, i.e. only its latest state is kept.
+type outboxEvent struct {
+	Uuid        types.UUID
+	ClusterUuid types.UUID
+	Kind        string
+	Name        string
+	Severity    string
+	Message     string
+	Url         string
+	Tags        string
+	ExtraTags   string
+	Labels      string
+	Annotations string
+	// Sinks are the names of the sinks the event is still to be sent to.
+	Sinks   string
+	Deleted types.Bool
+	// Version increases with every event stored, so that an event replaced in the meantime is not dequeued.
+	Version uint64
+	Created types.UnixMilli
+}
+
+func (outboxEvent) TableName() string {
+	return "notification_outbox"
+}
+
+// event returns the Event stored in the outbox.
+func (o outboxEvent) event() (Event, error) {
+	event := Event{
+		Uuid:        o.Uuid,
+		ClusterUuid: o.ClusterUuid,
+		Kind:        o.Kind,
+		Name:        o.Name,
+		Severity:    o.Severity,
+		Message:     o.Message,
+	}
+
+	u, err := url.Parse(o.Url)
+	if err != nil {
+		return Event{}, errors.Wrap(err, "cannot parse url")
+	}
+	event.URL = u
+
+	if err := json.Unmarshal([]byte(o.Tags), &event.Tags); err != nil {
+		return Event{}, errors.Wrap(err, "cannot decode tags")
+	}
+
+	if err := json.Unmarshal([]byte(o.ExtraTags), &event.ExtraTags); err != nil {
+		return Event{}, errors.Wrap(err, "cannot decode extra tags")
+	}
+
+	if err := json.Unmarshal([]byte(o.Labels), &event.Labels); err != nil {
+		return Event{}, errors.Wrap(err, "cannot decode labels")
+	}
+
+	if err := json.Unmarshal([]byte(o.Annotations), &event.Annotations); err != nil {
+		return Event{}, errors.Wrap(err, "cannot decode annotations")
+	}
+
+	event.deleted = o.Deleted.Valid && o.Deleted.Bool
+
+	return event, nil
+}
+
+// RestoreOutbox loads the resources with events in the outbox of the given cluster,
+// so that newer events of these resources are queued behind them.
+func (c *Client) RestoreOutbox(ctx context.Context, clusterUuid types.UUID) error {
+	var rows []struct {
+		Uuid    types.UUID
+		Version uint64
+	}
+	if err := c.db.SelectContext(
+		ctx,
+		&rows,
+		c.db.Rebind(`SELECT uuid, version FROM notification_outbox WHERE cluster_uuid = ?`),
+		clusterUuid,
+	); err != nil {
+		return errors.Wrap(err, "cannot restore notification outbox")
+	}
+
+	c.outboxMu.Lock()
+	defer c.outboxMu.Unlock()
+
+	for _, row := range rows {
+		c.pending[row.Uuid] = struct{}{}
+		c.outboxVersion = max(c.outboxVersion, row.Version)
+	}
+
+	if len(rows) > 0 {
+		klog.Infof("Restored %d notification events from outbox", len(rows))
+		c.wakeOutbox()
+	}
+
+	return nil
+}
+
+// enqueueIfPending stores the given event in the outbox if an earlier event of the same resource is
+// still pending there, so that the events of a resource are sent in order. Returns whether it did so.
+func (c *Client) enqueueIfPending(ctx context.Context, event Event) bool {
+	c.outboxMu.Lock()
+	defer c.outboxMu.Unlock()
+
+	if _, ok := c.pending[event.Uuid]; !ok {
+		return false
+	}
+
+	c.enqueueLocked(ctx, event, c.sinkNames())
+
+	return true
+}
+
+// enqueue stores the given event in the outbox for the sinks with the given names,
+// replacing any pending event of the same resource.
+func (c *Client) enqueue(ctx context.Context, event Event, sinks []string) {
+	c.outboxMu.Lock()
+	defer c.outboxMu.Unlock()
+
+	c.enqueueLocked(ctx, event, sinks)
+}
+
+func (c *Client) enqueueLocked(ctx context.Context, event Event, sinks []string) {
+	names, _ := json.Marshal(sinks)
+	tags, _ := json.Marshal(event.Tags)
+	extraTags, _ := json.Marshal(event.ExtraTags)
+	labels, _ := json.Marshal(event.Labels)
+	annotations, _ := json.Marshal(event.Annotations)
+
+	c.outboxVersion++
+
+	row := outboxEvent{
+		Uuid:        event.Uuid,
+		ClusterUuid: event.ClusterUuid,
+		Kind:        event.Kind,
+		Name:        event.Name,
+		Severity:    event.Severity,
+		Message:     event.Message,
+		Url:         event.URL.String(),
+		Tags:        string(tags),
+		ExtraTags:   string(extraTags),
+		Labels:      string(labels),
+		Annotations: string(annotations),
+		Sinks:       string(names),
+		Deleted:     types.Bool{Bool: event.deleted, Valid: true},
+		Version:     c.outboxVersion,
+		Created:     types.UnixMilli(time.Now()),
+	}
+
+	stmt, _ := c.db.BuildUpsertStmt(row)
+	if _, err := c.db.NamedExecContext(ctx, stmt, row); err != nil {
+		klog.Errorf("Cannot store event in notification outbox, dropping it: %v", err)
+
+		return
+	}
+
+	c.pending[event.Uuid] = struct{}{}
+	c.wakeOutbox()
+}
+
+// wakeOutbox notifies DrainOutbox about new events without blocking.
+func (c *Client) wakeOutbox() {
+	select {
+	case c.outboxSignal <- struct{}{}:
+	default:
+	}
+}
+
+// DrainOutbox sends the events of the given cluster stored in the outbox in the order they were stored.
+// If sending fails, it is retried with exponential backoff.
+func (c *Client) DrainOutbox(ctx context.Context, clusterUuid types.UUID) error {
+	b := backoff.NewExponentialWithJitter(time.Second, 5*time.Minute)
+	var attempt uint64
+
+	for {
+		if attempt > 0 {
+			select {
+			case <-time.After(b(attempt)):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		var rows []outboxEvent
+		if err := c.db.SelectContext(
+			ctx,
+			&rows,
+			c.db.Rebind(
+				c.db.BuildSelectStmt(outboxEvent{}, outboxEvent{})+` WHERE cluster_uuid = ? ORDER BY created LIMIT ?`,
+			),
+			clusterUuid,
+			outboxBatchSize,
+		); err != nil {
+			klog.Errorf("Cannot fetch events from notification outbox: %v", err)
+			attempt++
+
+			continue
+		}
+
+		if len(rows) == 0 {
+			attempt = 0
+
+			select {
+			case <-c.outboxSignal:
+				continue
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if attempt == 0 {
+			klog.Infof("Draining notification outbox (outbox_size: %d)", c.outboxSize())
+		}
+
+		for _, row := range rows {
+			event, err := row.event()
+			var sinks []string
+			if err == nil {
+				err = errors.Wrap(json.Unmarshal([]byte(row.Sinks), &sinks), "cannot decode sinks")
+			}
+			if err != nil {
+				klog.Errorf("Cannot restore event from notification outbox, dropping it: %v", err)
+				c.dequeue(ctx, row)
+
+				continue
+			}
+
+			if failed, err := c.send(ctx, event, sinks); err != nil {
+				attempt++
+				klog.Errorf("Cannot drain notification outbox, retrying (outbox_size: %d): %v", c.outboxSize(), err)
+				c.retain(ctx, row, failed)
+
+				break
+			}
+
+			attempt = 0
+			if event.deleted {
+				c.Forget(ctx, event.Uuid)
+			} else {
+				c.remember(ctx, event)
+			}
+			c.dequeue(ctx, row)
+		}
+	}
+}
+
+// dequeue removes the given event from the outbox unless it has been replaced by a newer one in the meantime.
+func (c *Client) dequeue(ctx context.Context, row outboxEvent) {
+	c.outboxMu.Lock()
+	defer c.outboxMu.Unlock()
+
+	res, err := c.db.ExecContext(
+		ctx,
+		c.db.Rebind(`DELETE FROM notification_outbox WHERE uuid = ? AND version = ?`),
+		row.Uuid,
+		row.Version,
+	)
+	if err != nil {
+		klog.Errorf("Cannot delete event from notification outbox: %v", err)
+
+		return
+	}
+
+	if affected, err := res.RowsAffected(); err == nil && affected > 0 {
+		delete(c.pending, row.Uuid)
+	}
+}
+
+// retain keeps the given event in the outbox only for the sinks with the given names,
+// so that it is not sent again to the other sinks. Does nothing if the event has been replaced in the meantime.
+func (c *Client) retain(ctx context.Context, row outboxEvent, sinks []string) {
+	names, _ := json.Marshal(sinks)
+
+	if _, err := c.db.ExecContext(
+		ctx,
+		c.db.Rebind(`UPDATE notification_outbox SET sinks = ? WHERE uuid = ? AND version = ?`),
+		string(names),
+		row.Uuid,
+		row.Version,
+	); err != nil {
+		klog.Errorf("Cannot update event in notification outbox: %v", err)
+	}
+}
+
+// outboxSize returns the number of events pending in the outbox.
+func (c *Client) outboxSize() int {
+	c.outboxMu.Lock()
+	defer c.outboxMu.Unlock()
+
+	return len(c.pending)
+}
@@ -0,0 +1,262 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/icinga/icinga-go-library/notifications/event"
+	"github.com/icinga/icinga-go-library/notifications/source"
+	"github.com/icinga/icinga-go-library/types"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/klog/v2"
+)
+
+// ruleConcurrency limits the number of SQL rules evaluated concurrently for an event.
+const ruleConcurrency = 8
+
+// rule is the decoded filter expression of an Icinga Notifications rule.
+type rule struct {
+	Version int `json:"version"`
+	// Kind restricts the rule to the resources of the given kind. Version 2 rules without kind match any kind.
+	Kind string `json:"kind"`
+
+	// Query and Args define version 1 rules, which match if the query returns a row for the resource.
+	Query string `json:"query"`
+	Args  []any  `json:"args"`
+
+	// Namespaces, Labels and Severities define version 2 rules, which are evaluated in memory.
+	// Labels is a Kubernetes label selector. Unset fields match any resource.
+	Namespaces []string `json:"namespaces"`
+	Labels     string   `json:"labels"`
+	Severities []string `json:"severities"`
+
+	selector   labels.Selector
+	severities []event.Severity
+}
+
+// parseRule decodes the given filter expression. An empty expression yields nil, which matches any resource.
+func parseRule(filterExpr string) (*rule, error) {
+	if filterExpr == "" {
+		return nil, nil
+	}
+
+	var r rule
+	if err := json.Unmarshal([]byte(filterExpr), &r); err != nil {
+		return nil, errors.Wrap(err, "cannot decode rule filter expression as JSON into struct")
+	}
+
+	switch r.Version {
+	case 1:
+		if r.Query == "" {
+			return nil, errors.New("version 1 rule without query")
+		}
+	case 2:
+		r.selector = labels.Everything()
+		if r.Labels != "" {
+			selector, err := labels.Parse(r.Labels)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid label selector")
+			}
+			r.selector = selector
+		}
+
+		for _, s := range r.Severities {
+			severity, err := event.ParseSeverity(s)
+			if err != nil {
+				return nil, errors.Wrap(err, "invalid severity")
+			}
+			r.severities = append(r.severities, severity)
+		}
+	default:
+		return nil, errors.Errorf("decoded rule filter expression .Version is %d, 1 or 2 expected", r.Version)
+	}
+
+	return &r, nil
+}
+
+// matches evaluates the version 2 rule against the given event.
+func (r *rule) matches(ev Event) bool {
+	if r.Kind != "" && r.Kind != ev.Kind {
+		return false
+	}
+
+	if len(r.Namespaces) > 0 && !slices.Contains(r.Namespaces, ev.Tags["namespace"]) {
+		return false
+	}
+
+	if !r.selector.Matches(labels.Set(ev.Labels)) {
+		return false
+	}
+
+	if len(r.severities) > 0 {
+		severity, err := event.ParseSeverity(ev.Severity)
+		if err != nil || !slices.Contains(r.severities, severity) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ruleCache holds the decoded rules of the latest rules version and the results of its SQL rules per resource.
+// Results are discarded once the rules version changes or the resource is updated or forgotten.
+type ruleCache struct {
+	mu      sync.Mutex
+	version string
+	rules   map[string]*rule
+	results map[types.UUID]map[string]bool
+}
+
+// prepare returns the decoded rules of the given rules info, decoding them if its version has changed.
+// Invalid rules are logged and never match.
+func (rc *ruleCache) prepare(info *source.RulesInfo) map[string]*rule {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.rules != nil && rc.version == info.Version {
+		return rc.rules
+	}
+
+	rules := make(map[string]*rule, len(info.Rules))
+	for id, filterExpr := range info.Rules {
+		r, err := parseRule(filterExpr)
+		if err != nil {
+			klog.Errorf("Ignoring rule %q with invalid filter expression %q: %v", id, filterExpr, err)
+
+			continue
+		}
+		rules[id] = r
+	}
+
+	rc.version = info.Version
+	rc.rules = rules
+	rc.results = make(map[types.UUID]map[string]bool)
+
+	return rules
+}
+
+// result returns the cached result of the given SQL rule of the given rules version for the given resource.
+func (rc *ruleCache) result(version, id string, uuid types.UUID) (matched bool, ok bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.version != version {
+		return false, false
+	}
+
+	matched, ok = rc.results[uuid][id]
+
+	return
+}
+
+// store caches the result of the given SQL rule of the given rules version for the given resource.
+func (rc *ruleCache) store(version, id string, uuid types.UUID, matched bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	if rc.version != version {
+		return
+	}
+
+	if rc.results[uuid] == nil {
+		rc.results[uuid] = make(map[string]bool)
+	}
+	rc.results[uuid][id] = matched
+}
+
+// forget discards the cached results for the given resource.
+func (rc *ruleCache) forget(uuid types.UUID) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	delete(rc.results, uuid)
+}
+
+// evaluateRules returns the IDs of the rules of the given rules info matching the given event.
+// Version 2 rules are evaluated in memory, version 1 rules run their SQL queries concurrently
+// unless their results are already cached.
+func (c *Client) evaluateRules(ctx context.Context, info *source.RulesInfo, ev Event) ([]string, error) {
+	rules := c.rules.prepare(info)
+
+	var mu sync.Mutex
+	ruleIds := make([]string, 0, len(rules))
+	match := func(id string) {
+		mu.Lock()
+		ruleIds = append(ruleIds, id)
+		mu.Unlock()
+	}
+
+	g, ctx := errgroup.WithContext(ctx)
+	g.SetLimit(ruleConcurrency)
+
+	for id, r := range rules {
+		switch {
+		case r == nil:
+			match(id)
+		case r.Version == 2:
+			if r.matches(ev) {
+				match(id)
+			}
+		case r.Kind != ev.Kind:
+			// Version 1 rules only apply to resources of their kind.
+		default:
+			if matched, ok := c.rules.result(info.Version, id, ev.Uuid); ok {
+				if matched {
+					match(id)
+				}
+
+				continue
+			}
+
+			g.Go(func() error {
+				matched, err := c.queryRule(ctx, r, ev.Uuid, ev.ClusterUuid)
+				if err != nil {
+					return errors.Wrapf(err, "cannot fetch rule %q from %q", id, r.Query)
+				}
+
+				c.rules.store(info.Version, id, ev.Uuid, matched)
+				if matched {
+					match(id)
+				}
+
+				return nil
+			})
+		}
+	}
+
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+
+	sort.Strings(ruleIds)
+
+	return ruleIds, nil
+}
+
+// queryRule returns whether the query of the given version 1 rule returns a row for the given resource.
+func (c *Client) queryRule(ctx context.Context, r *rule, uuid, clusterUuid types.UUID) (bool, error) {
+	args := make([]any, 0, len(r.Args))
+	for _, param := range r.Args {
+		switch param {
+		case ":uuid":
+			args = append(args, uuid)
+		case ":cluster_uuid":
+			args = append(args, clusterUuid)
+		default:
+			args = append(args, param)
+		}
+	}
+
+	rows, err := c.db.QueryContext(ctx, c.db.Rebind(r.Query), args...)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	return rows.Next(), nil
+}
@@ -0,0 +1,88 @@
+package notifications
+
+import "testing"
+
+func TestParseRule(t *testing.T) {
+	tests := []struct {
+		name       string
+		filterExpr string
+		nil        bool
+		error      bool
+	}{
+		{name: "empty", filterExpr: "", nil: true},
+		{name: "invalid-json", filterExpr: "{", error: true},
+		{name: "unknown-version", filterExpr: `{"version": 3}`, error: true},
+		{name: "missing-version", filterExpr: `{"query": "SELECT 1"}`, error: true},
+		{name: "v1", filterExpr: `{"version": 1, "kind": "pod", "query": "SELECT 1 FROM pod WHERE uuid = ?"}`},
+		{name: "v1-without-query", filterExpr: `{"version": 1, "kind": "pod"}`, error: true},
+		{name: "v2-empty", filterExpr: `{"version": 2}`},
+		{name: "v2", filterExpr: `{"version": 2, "namespaces": ["default"], "labels": "app in (web)", "severities": ["crit"]}`},
+		{name: "v2-invalid-selector", filterExpr: `{"version": 2, "labels": "app in (web"}`, error: true},
+		{name: "v2-invalid-severity", filterExpr: `{"version": 2, "severities": ["fatal"]}`, error: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseRule(tt.filterExpr)
+			if tt.error {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if (r == nil) != tt.nil {
+				t.Errorf("parseRule() = %v, want nil: %t", r, tt.nil)
+			}
+		})
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	ev := Event{
+		Kind:     "pod",
+		Severity: "crit",
+		Tags:     map[string]string{"namespace": "default"},
+		Labels:   map[string]string{"app": "web", "tier": "frontend"},
+	}
+
+	tests := []struct {
+		name       string
+		filterExpr string
+		want       bool
+	}{
+		{name: "any", filterExpr: `{"version": 2}`, want: true},
+		{name: "kind", filterExpr: `{"version": 2, "kind": "pod"}`, want: true},
+		{name: "other-kind", filterExpr: `{"version": 2, "kind": "node"}`, want: false},
+		{name: "namespace", filterExpr: `{"version": 2, "namespaces": ["kube-system", "default"]}`, want: true},
+		{name: "other-namespace", filterExpr: `{"version": 2, "namespaces": ["kube-system"]}`, want: false},
+		{name: "labels", filterExpr: `{"version": 2, "labels": "app=web,tier"}`, want: true},
+		{name: "other-labels", filterExpr: `{"version": 2, "labels": "app=db"}`, want: false},
+		{name: "missing-label", filterExpr: `{"version": 2, "labels": "team"}`, want: false},
+		{name: "severity", filterExpr: `{"version": 2, "severities": ["warning", "crit"]}`, want: true},
+		{name: "other-severity", filterExpr: `{"version": 2, "severities": ["warning"]}`, want: false},
+		{
+			name:       "all",
+			filterExpr: `{"version": 2, "kind": "pod", "namespaces": ["default"], "labels": "app!=db", "severities": ["crit"]}`,
+			want:       true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := parseRule(tt.filterExpr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if got := r.matches(ev); got != tt.want {
+				t.Errorf("matches() = %t, want %t", got, tt.want)
+			}
+		})
+	}
+}
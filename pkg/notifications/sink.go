@@ -0,0 +1,153 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/icinga/icinga-kubernetes/pkg/com"
+	"github.com/pkg/errors"
+)
+
+// Sink types, see SinkConfig.Type.
+const (
+	SinkTypeWebhook      = "webhook"
+	SinkTypeAlertmanager = "alertmanager"
+)
+
+// Sink is a destination events are sent to.
+type Sink interface {
+	// Name identifies the sink in log messages.
+	Name() string
+	// Send sends the given event. Its URL is already resolved against the Icinga for Kubernetes Web URL.
+	Send(ctx context.Context, event Event) error
+}
+
+// SinkConfig defines a sink.
+type SinkConfig struct {
+	// Name identifies the sink, e.g. in log messages and in the outbox, and must be unique. Defaults to its URL.
+	Name string `yaml:"name"`
+	// Type is either SinkTypeWebhook or SinkTypeAlertmanager.
+	Type     string            `yaml:"type"`
+	Url      string            `yaml:"url"`
+	Username string            `yaml:"username"`
+	Password string            `yaml:"password"`
+	Insecure bool              `yaml:"insecure"`
+	Headers  map[string]string `yaml:"headers"`
+	// Timeout limits the duration of each request. Defaults to 10 seconds.
+	Timeout time.Duration `yaml:"timeout"`
+
+	// Template is a Go template producing the request body of webhooks.
+	// If not set, the event is sent as JSON object.
+	Template string `yaml:"template"`
+	// Secret is the key webhook request bodies are signed with using HMAC-SHA256, if set.
+	Secret string `yaml:"secret"`
+
+	// ResolveTimeout is the duration after which Alertmanager considers alerts resolved unless they are sent again.
+	// Defaults to 24 hours.
+	ResolveTimeout time.Duration `yaml:"resolve_timeout"`
+
+	url      *url.URL
+	template *template.Template
+}
+
+// Validate checks constraints in the supplied sink configuration and returns an error if they are violated.
+func (c *SinkConfig) Validate() error {
+	switch c.Type {
+	case SinkTypeWebhook, SinkTypeAlertmanager:
+	default:
+		return errors.Errorf("'type' must be either %q or %q", SinkTypeWebhook, SinkTypeAlertmanager)
+	}
+
+	if c.Url == "" {
+		return errors.New("'url' required")
+	}
+
+	var err error
+	if c.url, err = url.Parse(c.Url); err != nil {
+		return errors.Wrap(err, "'url' invalid")
+	}
+
+	if c.Password != "" && c.Username == "" {
+		return errors.New("'password' requires 'username'")
+	}
+
+	if c.Timeout < 0 || c.ResolveTimeout < 0 {
+		return errors.New("'timeout' and 'resolve_timeout' must not be negative")
+	}
+
+	if c.Template != "" {
+		if c.Type != SinkTypeWebhook {
+			return errors.New("'template' is only supported by webhooks")
+		}
+
+		if c.template, err = template.New("body").Funcs(templateFuncs).Parse(c.Template); err != nil {
+			return errors.Wrap(err, "'template' invalid")
+		}
+	}
+
+	if c.Secret != "" && c.Type != SinkTypeWebhook {
+		return errors.New("'secret' is only supported by webhooks")
+	}
+
+	if c.Name == "" {
+		c.Name = c.Url
+	}
+
+	if c.Timeout == 0 {
+		c.Timeout = 10 * time.Second
+	}
+
+	if c.ResolveTimeout == 0 {
+		c.ResolveTimeout = 24 * time.Hour
+	}
+
+	return nil
+}
+
+// NewSink creates the sink defined by the given validated configuration.
+func NewSink(c SinkConfig) Sink {
+	client := http.Client{
+		Timeout: c.Timeout,
+		Transport: &com.BasicAuthTransport{
+			RoundTripper: http.DefaultTransport,
+			Username:     c.Username,
+			Password:     c.Password,
+			Insecure:     c.Insecure,
+		},
+	}
+
+	if c.Type == SinkTypeAlertmanager {
+		return &AlertmanagerSink{config: c, client: client}
+	}
+
+	return &WebhookSink{config: c, client: client}
+}
+
+// do sends a POST request with the given JSON body and headers and checks that it succeeded.
+func do(ctx context.Context, client http.Client, u string, body []byte, headers map[string]string) error {
+	r, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return errors.Wrap(err, "cannot create request")
+	}
+
+	r.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		r.Header.Set(name, value)
+	}
+
+	res, err := client.Do(r)
+	if err != nil {
+		return errors.Wrap(err, "cannot send request")
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return errors.Errorf("request failed with status %s", res.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,197 @@
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/icinga/icinga-go-library/types"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// State is the last severity and message sent to Icinga Notifications for a resource.
+type State struct {
+	Uuid        types.UUID
+	ClusterUuid types.UUID
+	Kind        string
+	Severity    string
+	Message     string
+	Sent        types.UnixMilli
+}
+
+func (State) TableName() string {
+	return "notification_state"
+}
+
+// kindPattern matches the kinds of events, which are the table names of the resources.
+var kindPattern = regexp.MustCompile(`^[a-z_]+$`)
+
+// RestoreStates loads the states of the given cluster persisted by previous runs,
+// so that events are not sent again after a restart unless their severity has changed.
+func (c *Client) RestoreStates(ctx context.Context, clusterUuid types.UUID) error {
+	var states []State
+	if err := c.db.SelectContext(
+		ctx,
+		&states,
+		c.db.Rebind(c.db.BuildSelectStmt(State{}, State{})+` WHERE cluster_uuid = ?`),
+		clusterUuid,
+	); err != nil {
+		return errors.Wrap(err, "cannot restore notification states")
+	}
+
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	for _, state := range states {
+		c.states[state.Uuid] = state
+	}
+
+	klog.V(2).Infof("Restored %d notification states", len(states))
+
+	return nil
+}
+
+// PruneStates removes the persisted states of the given cluster whose resources no longer exist,
+// e.g. of resources deleted while not running.
+func (c *Client) PruneStates(ctx context.Context, clusterUuid types.UUID) error {
+	var kinds []string
+	if err := c.db.SelectContext(
+		ctx,
+		&kinds,
+		c.db.Rebind(`SELECT DISTINCT kind FROM notification_state WHERE cluster_uuid = ?`),
+		clusterUuid,
+	); err != nil {
+		return errors.Wrap(err, "cannot fetch kinds of notification states")
+	}
+
+	for _, kind := range kinds {
+		if !kindPattern.MatchString(kind) {
+			continue
+		}
+
+		if _, err := c.db.ExecContext(ctx, c.db.Rebind(fmt.Sprintf(
+			`DELETE FROM notification_state WHERE cluster_uuid = ? AND kind = ? AND NOT EXISTS `+
+				`(SELECT 1 FROM %s r WHERE r.uuid = notification_state.uuid)`,
+			kind,
+		)), clusterUuid, kind); err != nil {
+			return errors.Wrapf(err, "cannot prune notification states of %s", kind)
+		}
+	}
+
+	return nil
+}
+
+// Forget removes the state of the given resource, e.g. after its incident has been resolved on deletion.
+func (c *Client) Forget(ctx context.Context, id types.UUID) {
+	c.stateMu.Lock()
+	delete(c.last, id)
+	delete(c.states, id)
+	delete(c.causes, id)
+	if timer, ok := c.rechecks[id]; ok {
+		timer.Stop()
+		delete(c.rechecks, id)
+	}
+	c.stateMu.Unlock()
+
+	if _, err := c.db.ExecContext(ctx, c.db.Rebind(`DELETE FROM notification_state WHERE uuid = ?`), id); err != nil {
+		klog.Errorf("Cannot delete notification state: %v", err)
+	}
+
+	c.rules.forget(id)
+	c.owners.invalidate(id)
+	c.release(ctx, id)
+}
+
+// changed returns whether the severity of the given event differs from the last one sent.
+func (c *Client) changed(event Event) bool {
+	c.stateMu.Lock()
+	defer c.stateMu.Unlock()
+
+	state, ok := c.states[event.Uuid]
+
+	return !ok || state.Severity != event.Severity
+}
+
+// remember records the given event as sent.
+func (c *Client) remember(ctx context.Context, event Event) {
+	state := State{
+		Uuid:        event.Uuid,
+		ClusterUuid: event.ClusterUuid,
+		Kind:        event.Kind,
+		Severity:    event.Severity,
+		Message:     event.Message,
+		Sent:        types.UnixMilli(time.Now()),
+	}
+
+	c.stateMu.Lock()
+	c.states[event.Uuid] = state
+	c.stateMu.Unlock()
+
+	stmt, _ := c.db.BuildUpsertStmt(state)
+	if _, err := c.db.NamedExecContext(ctx, stmt, state); err != nil {
+		klog.Errorf("Cannot persist notification state: %v", err)
+	}
+
+	if event.Severity == "ok" {
+		c.release(ctx, event.Uuid)
+	}
+}
+
+// Resend sends the last event of each resource again whose state has not been sent
+// for at least the configured resend interval, so that Icinga Notifications does not consider it stale.
+func (c *Client) Resend(ctx context.Context) {
+	if c.resendInterval <= 0 {
+		return
+	}
+
+	var due []Event
+	c.stateMu.Lock()
+	for id, event := range c.last {
+		if _, caused := c.causes[id]; caused && c.correlation == CorrelationSuppress {
+			continue
+		}
+
+		if state, ok := c.states[id]; ok && time.Since(state.Sent.Time()) >= c.resendInterval {
+			due = append(due, event)
+		}
+	}
+	c.stateMu.Unlock()
+
+	for _, event := range due {
+		if err := ctx.Err(); err != nil {
+			return
+		}
+
+		c.resend(ctx, event)
+	}
+}
+
+// resend sends the given event again unless it is suppressed or has been replaced in the meantime.
+func (c *Client) resend(ctx context.Context, event Event) {
+	defer c.locks.lock(event.Uuid)()
+
+	c.stateMu.Lock()
+	last, ok := c.last[event.Uuid]
+	state, sent := c.states[event.Uuid]
+	c.stateMu.Unlock()
+
+	// The event may have been handled or its resource deleted while waiting for the lock.
+	if !ok || !sent || time.Since(state.Sent.Time()) < c.resendInterval {
+		return
+	}
+
+	event, suppressed, _ := c.applyAnnotations(last)
+	if _, ok := c.inDowntime(event); suppressed || ok {
+		return
+	}
+
+	if _, err := c.send(ctx, event, nil); err != nil {
+		klog.Errorf("Cannot resend event: %v", err)
+
+		return
+	}
+
+	c.remember(ctx, event)
+}
@@ -0,0 +1,95 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// WebhookSignatureHeader is the header webhook requests carry the HMAC-SHA256 signature of their body in,
+// formatted as "sha256=<hex digest>", if a secret is configured.
+const WebhookSignatureHeader = "X-Icinga-Signature"
+
+// templateFuncs are the functions available in webhook templates in addition to the predefined ones.
+var templateFuncs = template.FuncMap{
+	// json encodes the given value as JSON, e.g. to safely embed strings.
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+
+		return string(b), err
+	},
+}
+
+// webhookPayload is the data webhook bodies are generated from.
+type webhookPayload struct {
+	Uuid        string            `json:"uuid"`
+	ClusterUuid string            `json:"cluster_uuid"`
+	Kind        string            `json:"kind"`
+	Name        string            `json:"name"`
+	Severity    string            `json:"severity"`
+	Message     string            `json:"message"`
+	Url         string            `json:"url"`
+	Tags        map[string]string `json:"tags"`
+	ExtraTags   map[string]string `json:"extra_tags"`
+}
+
+// WebhookSink sends events as HTTP POST requests with a JSON body.
+type WebhookSink struct {
+	config SinkConfig
+	client http.Client
+}
+
+// Name implements the Sink interface.
+func (w *WebhookSink) Name() string {
+	return w.config.Name
+}
+
+// Send implements the Sink interface.
+func (w *WebhookSink) Send(ctx context.Context, event Event) error {
+	payload := webhookPayload{
+		Uuid:        event.Uuid.String(),
+		ClusterUuid: event.ClusterUuid.String(),
+		Kind:        event.Kind,
+		Name:        event.Name,
+		Severity:    event.Severity,
+		Message:     event.Message,
+		Url:         event.URL.String(),
+		Tags:        event.Tags,
+		ExtraTags:   event.ExtraTags,
+	}
+
+	var body []byte
+	if w.config.template != nil {
+		var buf bytes.Buffer
+		if err := w.config.template.Execute(&buf, payload); err != nil {
+			return errors.Wrap(err, "cannot execute webhook template")
+		}
+		body = buf.Bytes()
+	} else {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return errors.Wrap(err, "cannot encode event")
+		}
+	}
+
+	headers := w.config.Headers
+	if w.config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(w.config.Secret))
+		mac.Write(body)
+
+		headers = make(map[string]string, len(w.config.Headers)+1)
+		for name, value := range w.config.Headers {
+			headers[name] = value
+		}
+		headers[WebhookSignatureHeader] = "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	return do(ctx, w.client, w.config.Url, body, headers)
+}
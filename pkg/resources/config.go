@@ -0,0 +1,66 @@
+package resources
+
+import (
+	"slices"
+
+	"github.com/pkg/errors"
+)
+
+// Config maps resource names, see Kinds, to the options of that kind.
+// Resource kinds not configured use their defaults.
+type Config map[string]KindConfig
+
+// KindConfig overrides the default options of a resource kind. Options that are not set keep their defaults.
+type KindConfig struct {
+	Enabled       *bool `yaml:"enabled"`
+	NoDelete      *bool `yaml:"no_delete"`
+	NoWarmup      *bool `yaml:"no_warmup"`
+	Notifications *bool `yaml:"notifications"`
+}
+
+// Options returns the options of the given kind, i.e. its defaults overridden by the configured options.
+func (c Config) Options(kind Kind) Options {
+	options := kind.Defaults
+	kc := c[kind.Name]
+
+	if kc.Enabled != nil {
+		options.Enabled = *kc.Enabled
+	}
+
+	if kc.NoDelete != nil {
+		options.NoDelete = *kc.NoDelete
+	}
+
+	if kc.NoWarmup != nil {
+		options.NoWarmup = *kc.NoWarmup
+	}
+
+	if kc.Notifications != nil {
+		options.Notifications = *kc.Notifications
+	}
+
+	return options
+}
+
+// Enabled returns whether resources of the given name, see Kinds, are synchronized.
+func (c Config) Enabled(name string) bool {
+	i := slices.IndexFunc(Kinds, func(kind Kind) bool { return kind.Name == name })
+
+	return i >= 0 && c.Options(Kinds[i]).Enabled
+}
+
+// Validate checks constraints in the supplied resources configuration and returns an error if they are violated.
+func (c Config) Validate() error {
+	for name, kc := range c {
+		i := slices.IndexFunc(Kinds, func(kind Kind) bool { return kind.Name == name })
+		if i < 0 {
+			return errors.Errorf("unknown resource %q in 'resources'", name)
+		}
+
+		if kc.Notifications != nil && *kc.Notifications && !Kinds[i].Notifiable {
+			return errors.Errorf("notifications are not supported for resource %q", name)
+		}
+	}
+
+	return nil
+}
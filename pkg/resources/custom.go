@@ -0,0 +1,55 @@
+package resources
+
+import (
+	"github.com/pkg/errors"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// CustomResource defines a resource of an arbitrary API, e.g. defined by a CustomResourceDefinition,
+// that is synchronized via the dynamic client.
+type CustomResource struct {
+	Group    string `yaml:"group"`
+	Version  string `yaml:"version"`
+	Resource string `yaml:"resource"`
+	// NoDelete, NoWarmup and Notifications are the options of the resource as described in Options.
+	NoDelete      bool `yaml:"no_delete"`
+	NoWarmup      bool `yaml:"no_warmup"`
+	Notifications bool `yaml:"notifications"`
+}
+
+// GroupVersionResource returns the group, version and resource name of the resource.
+func (c CustomResource) GroupVersionResource() kschema.GroupVersionResource {
+	return kschema.GroupVersionResource{Group: c.Group, Version: c.Version, Resource: c.Resource}
+}
+
+// Options returns the options of the resource.
+func (c CustomResource) Options() Options {
+	return Options{
+		Enabled:       true,
+		NoDelete:      c.NoDelete,
+		NoWarmup:      c.NoWarmup,
+		Notifications: c.Notifications,
+	}
+}
+
+// CustomResources lists the custom resources to synchronize.
+type CustomResources []CustomResource
+
+// Validate checks constraints in the supplied custom resources configuration and
+// returns an error if they are violated.
+func (c CustomResources) Validate() error {
+	seen := make(map[kschema.GroupResource]struct{}, len(c))
+	for _, cr := range c {
+		if cr.Version == "" || cr.Resource == "" {
+			return errors.Errorf("'version' and 'resource' of custom resource %q must not be empty", cr.Group)
+		}
+
+		gr := cr.GroupVersionResource().GroupResource()
+		if _, ok := seen[gr]; ok {
+			return errors.Errorf("custom resource %q configured more than once", gr)
+		}
+		seen[gr] = struct{}{}
+	}
+
+	return nil
+}
@@ -0,0 +1,236 @@
+package resources
+
+import (
+	cachev1 "github.com/icinga/icinga-kubernetes/internal/cache/v1"
+	schemav1 "github.com/icinga/icinga-kubernetes/pkg/schema/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	kcache "k8s.io/client-go/tools/cache"
+)
+
+// Options defines how resources of a kind are synchronized.
+type Options struct {
+	// Enabled defines whether resources of the kind are synchronized at all.
+	Enabled bool
+	// NoDelete defines whether rows of deleted resources are kept in the database.
+	NoDelete bool
+	// NoWarmup defines whether existing rows are not loaded from the database before synchronizing,
+	// so that rows of resources deleted while not running are kept.
+	NoWarmup bool
+	// Notifications defines whether resources of the kind are forwarded to Icinga Notifications, if configured.
+	Notifications bool
+}
+
+// Kind describes a kind of Kubernetes resources that can be synchronized.
+type Kind struct {
+	// Name is the name of the resource, as also used in the scope configuration.
+	Name string
+	// LogName is the name of the logger of the synchronization.
+	LogName string
+	// Informer returns the informer for the kind from the given factory.
+	Informer func(informers.SharedInformerFactory) kcache.SharedIndexInformer
+	// Factory returns a function that creates the database representation of the kind.
+	Factory func(*kubernetes.Clientset) func() schemav1.Resource
+	// Multiplexer receives the upserted and deleted resources of the kind, if not nil.
+	Multiplexer cachev1.EventsMultiplexer
+	// Multiplexed defines whether resources are always sent to Multiplexer
+	// as the synchronization of other resources depends on them.
+	// Otherwise, they are only sent if forwarded to Icinga Notifications.
+	Multiplexed bool
+	// Notifiable defines whether resources of the kind can be forwarded to Icinga Notifications.
+	Notifiable bool
+	// Defaults are the options used if not configured otherwise.
+	Defaults Options
+}
+
+// Kinds lists all kinds of resources that can be synchronized in the order they are started.
+var Kinds = []Kind{
+	{
+		Name:    "namespaces",
+		LogName: "namespaces",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Core().V1().Namespaces().Informer()
+		},
+		Factory:  constructor(schemav1.NewNamespace),
+		Defaults: Options{Enabled: true},
+	},
+	{
+		Name:    "nodes",
+		LogName: "nodes",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Core().V1().Nodes().Informer()
+		},
+		Factory:     constructor(schemav1.NewNode),
+		Multiplexer: cachev1.Multiplexers().Nodes(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "pods",
+		LogName: "pods",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Core().V1().Pods().Informer()
+		},
+		Factory: func(clientset *kubernetes.Clientset) func() schemav1.Resource {
+			return schemav1.NewPodFactory(clientset).New
+		},
+		Multiplexer: cachev1.Multiplexers().Pods(),
+		Multiplexed: true,
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "deployments",
+		LogName: "deployments",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Apps().V1().Deployments().Informer()
+		},
+		Factory:     constructor(schemav1.NewDeployment),
+		Multiplexer: cachev1.Multiplexers().Deployments(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "daemonsets",
+		LogName: "daemon-sets",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Apps().V1().DaemonSets().Informer()
+		},
+		Factory:     constructor(schemav1.NewDaemonSet),
+		Multiplexer: cachev1.Multiplexers().DaemonSets(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "replicasets",
+		LogName: "replica-sets",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Apps().V1().ReplicaSets().Informer()
+		},
+		Factory:     constructor(schemav1.NewReplicaSet),
+		Multiplexer: cachev1.Multiplexers().ReplicaSets(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "statefulsets",
+		LogName: "stateful-sets",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Apps().V1().StatefulSets().Informer()
+		},
+		Factory:     constructor(schemav1.NewStatefulSet),
+		Multiplexer: cachev1.Multiplexers().StatefulSets(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "services",
+		LogName: "services",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Core().V1().Services().Informer()
+		},
+		Factory: func(clientset *kubernetes.Clientset) func() schemav1.Resource {
+			return schemav1.NewServiceFactory(clientset).NewService
+		},
+		Multiplexer: cachev1.Multiplexers().Services(),
+		Multiplexed: true,
+		Defaults:    Options{Enabled: true},
+	},
+	{
+		Name:    "endpointslices",
+		LogName: "endpoints",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Discovery().V1().EndpointSlices().Informer()
+		},
+		Factory:  constructor(schemav1.NewEndpointSlice),
+		Defaults: Options{Enabled: true},
+	},
+	{
+		Name:    "secrets",
+		LogName: "secrets",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Core().V1().Secrets().Informer()
+		},
+		Factory:  constructor(schemav1.NewSecret),
+		Defaults: Options{Enabled: true},
+	},
+	{
+		Name:    "configmaps",
+		LogName: "config-maps",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Core().V1().ConfigMaps().Informer()
+		},
+		Factory:  constructor(schemav1.NewConfigMap),
+		Defaults: Options{Enabled: true},
+	},
+	{
+		Name:    "events",
+		LogName: "events",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Events().V1().Events().Informer()
+		},
+		Factory: constructor(schemav1.NewEvent),
+		// Events are removed by the periodic cleanup of the event table instead.
+		Defaults: Options{Enabled: true, NoDelete: true, NoWarmup: true},
+	},
+	{
+		Name:    "persistentvolumeclaims",
+		LogName: "pvcs",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Core().V1().PersistentVolumeClaims().Informer()
+		},
+		Factory:     constructor(schemav1.NewPvc),
+		Multiplexer: cachev1.Multiplexers().PersistentVolumeClaims(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "jobs",
+		LogName: "jobs",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Batch().V1().Jobs().Informer()
+		},
+		Factory:     constructor(schemav1.NewJob),
+		Multiplexer: cachev1.Multiplexers().Jobs(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "cronjobs",
+		LogName: "cron-jobs",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Batch().V1().CronJobs().Informer()
+		},
+		Factory:     constructor(schemav1.NewCronJob),
+		Multiplexer: cachev1.Multiplexers().CronJobs(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+	{
+		Name:    "ingresses",
+		LogName: "ingresses",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Networking().V1().Ingresses().Informer()
+		},
+		Factory:  constructor(schemav1.NewIngress),
+		Defaults: Options{Enabled: true},
+	},
+	{
+		Name:    "persistentvolumes",
+		LogName: "persistent-volumes",
+		Informer: func(f informers.SharedInformerFactory) kcache.SharedIndexInformer {
+			return f.Core().V1().PersistentVolumes().Informer()
+		},
+		Factory:     constructor(schemav1.NewPersistentVolume),
+		Multiplexer: cachev1.Multiplexers().PersistentVolumes(),
+		Notifiable:  true,
+		Defaults:    Options{Enabled: true, Notifications: true},
+	},
+}
+
+// constructor returns a Kind.Factory for resources that do not require a clientset.
+func constructor(fn func() schemav1.Resource) func(*kubernetes.Clientset) func() schemav1.Resource {
+	return func(*kubernetes.Clientset) func() schemav1.Resource {
+		return fn
+	}
+}
@@ -7,3 +7,13 @@ type Annotation struct {
 	Name  string
 	Value string
 }
+
+// annotationMap returns the given annotations as a map of names to values.
+func annotationMap(annotations []Annotation) map[string]string {
+	m := make(map[string]string, len(annotations))
+	for _, annotation := range annotations {
+		m[annotation.Name] = annotation.Value
+	}
+
+	return m
+}
@@ -9,11 +9,13 @@ import (
 	"github.com/icinga/icinga-go-library/com"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
+	"github.com/icinga/icinga-kubernetes/pkg/notifications"
 	"golang.org/x/sync/errgroup"
 	"io"
 	kcorev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
+	"net/url"
 	"strings"
 	"sync"
 	"time"
@@ -118,7 +120,7 @@ func (c *ContainerCommon) Relations() []database.Relation {
 		// Allow to automatically remove the logs when a container is deleted. Otherwise, we will have some dangling
 		// container logs in the database if the logs aren't deleted before removing the container, since any error
 		// can interrupt the deletion process of the logs when using the `on success` mechanism.
-		database.HasOne(ContainerLog{}, fk),
+		database.HasOne(ContainerLog{}, fk, database.WithoutStaleDelete()),
 	}
 }
 
@@ -227,6 +229,104 @@ func NewContainer(podUuid types.UUID, container kcorev1.Container, status kcorev
 	return c
 }
 
+// PodContainer is a container of a pod that can be forwarded to Icinga Notifications.
+type PodContainer struct {
+	*ContainerCommon
+	// Kind is the table name of the container, i.e. container, init_container or sidecar_container.
+	Kind string
+	Pod  *Pod
+}
+
+func (c PodContainer) MarshalEvent() (notifications.Event, error) {
+	return notifications.Event{
+		Uuid:        c.Uuid,
+		ClusterUuid: c.Pod.ClusterUuid,
+		Kind:        c.Kind,
+		Name:        c.Pod.Namespace + "/" + c.Pod.Name + "/" + c.Name,
+		Severity:    c.IcingaState.ToSeverity(),
+		Message:     c.IcingaStateReason,
+		URL:         &url.URL{Path: "/" + strings.ReplaceAll(c.Kind, "_", ""), RawQuery: fmt.Sprintf("id=%s", c.Uuid)},
+		Tags: map[string]string{
+			"uuid":         c.Uuid.String(),
+			"cluster_uuid": c.Pod.ClusterUuid.String(),
+			"name":         c.Name,
+			"namespace":    c.Pod.Namespace,
+			"pod":          c.Pod.Name,
+			"resource":     c.Kind,
+		},
+		ExtraTags:   nodeExtraTags(c.Pod.NodeName),
+		Labels:      labelMap(c.Pod.Labels),
+		Annotations: annotationMap(c.Pod.Annotations),
+	}, nil
+}
+
+// PodContainers returns all containers of the given pod, including init and sidecar containers.
+func PodContainers(pod *Pod) []PodContainer {
+	containers := make([]PodContainer, 0, len(pod.Containers)+len(pod.InitContainers)+len(pod.SidecarContainers))
+
+	for _, c := range pod.InitContainers {
+		containers = append(containers, PodContainer{ContainerCommon: &c.ContainerCommon, Kind: "init_container", Pod: pod})
+	}
+
+	for _, c := range pod.SidecarContainers {
+		containers = append(containers, PodContainer{ContainerCommon: &c.ContainerCommon, Kind: "sidecar_container", Pod: pod})
+	}
+
+	for _, c := range pod.Containers {
+		containers = append(containers, PodContainer{ContainerCommon: &c.ContainerCommon, Kind: "container", Pod: pod})
+	}
+
+	return containers
+}
+
+// StreamContainers consumes the upserted and deleted pods from the `upsertPods` and `deletePods` chans and
+// streams their containers to the `upserts` chan and the UUIDs of the containers of deleted pods to
+// the `deletes` chan, so that containers can be forwarded to Icinga Notifications individually.
+func StreamContainers(ctx context.Context, upsertPods, deletePods <-chan any, upserts, deletes chan<- any) error {
+	// containers holds the container UUIDs per pod UUID, as deleted pods are only streamed by their UUID.
+	containers := make(map[types.UUID][]types.UUID)
+
+	for {
+		select {
+		case e, more := <-upsertPods:
+			if !more {
+				return nil
+			}
+
+			pod := e.(*Pod)
+
+			uuids := make([]types.UUID, 0, len(containers[pod.Uuid]))
+			for _, container := range PodContainers(pod) {
+				uuids = append(uuids, container.Uuid)
+
+				select {
+				case upserts <- container:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			containers[pod.Uuid] = uuids
+		case id, more := <-deletePods:
+			if !more {
+				return nil
+			}
+
+			// The same UUID may be received multiple times, e.g. once per deleted relation,
+			// but the containers are only known the first time.
+			for _, containerUuid := range containers[id.(types.UUID)] {
+				select {
+				case deletes <- containerUuid:
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			}
+			delete(containers, id.(types.UUID))
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 type ContainerDevice struct {
 	ContainerUuid types.UUID
 	PodUuid       types.UUID
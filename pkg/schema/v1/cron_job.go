@@ -5,11 +5,13 @@ import (
 	"fmt"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
+	"github.com/icinga/icinga-kubernetes/pkg/notifications"
 	kbatchv1 "k8s.io/api/batch/v1"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kruntime "k8s.io/apimachinery/pkg/runtime"
 	kserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -126,6 +128,27 @@ func (c *CronJob) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 	c.Yaml = string(output)
 }
 
+func (c *CronJob) MarshalEvent() (notifications.Event, error) {
+	return notifications.Event{
+		Uuid:        c.Uuid,
+		ClusterUuid: c.ClusterUuid,
+		Kind:        "cron_job",
+		Name:        c.Namespace + "/" + c.Name,
+		Severity:    c.IcingaState.ToSeverity(),
+		Message:     c.IcingaStateReason,
+		URL:         &url.URL{Path: "/cronjob", RawQuery: fmt.Sprintf("id=%s", c.Uuid)},
+		Tags: map[string]string{
+			"uuid":         c.Uuid.String(),
+			"cluster_uuid": c.ClusterUuid.String(),
+			"name":         c.Name,
+			"namespace":    c.Namespace,
+			"resource":     "cron_job",
+		},
+		Labels:      labelMap(c.Labels),
+		Annotations: annotationMap(c.Annotations),
+	}, nil
+}
+
 func (c *CronJob) getIcingaState() (IcingaState, string) {
 	now := time.Now()
 
@@ -170,6 +193,6 @@ func (c *CronJob) Relations() []database.Relation {
 		database.HasMany(c.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(c.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(c.CronJobAnnotations, fk),
-		database.HasMany(c.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(c.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
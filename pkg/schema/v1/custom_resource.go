@@ -0,0 +1,248 @@
+package v1
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/icinga/icinga-go-library/strcase"
+	"github.com/icinga/icinga-go-library/types"
+	"github.com/icinga/icinga-kubernetes/pkg/database"
+	"github.com/icinga/icinga-kubernetes/pkg/notifications"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kruntime "k8s.io/apimachinery/pkg/runtime"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+)
+
+// CustomResource is a resource of an arbitrary API, e.g. defined by a CustomResourceDefinition,
+// synchronized via the dynamic client. Resources of all APIs share the same tables.
+type CustomResource struct {
+	Meta
+	ApiGroup                  string
+	ApiVersion                string
+	Resource                  string
+	Kind                      string
+	Yaml                      string
+	IcingaState               IcingaState
+	IcingaStateReason         string
+	Conditions                []CustomResourceCondition  `db:"-"`
+	Labels                    []Label                    `db:"-"`
+	CustomResourceLabels      []CustomResourceLabel      `db:"-"`
+	ResourceLabels            []ResourceLabel            `db:"-"`
+	Annotations               []Annotation               `db:"-"`
+	CustomResourceAnnotations []CustomResourceAnnotation `db:"-"`
+	ResourceAnnotations       []ResourceAnnotation       `db:"-"`
+}
+
+type CustomResourceCondition struct {
+	CustomResourceUuid types.UUID
+	Type               string
+	Status             string
+	LastTransition     types.UnixMilli
+	Reason             string
+	Message            string
+}
+
+type CustomResourceLabel struct {
+	CustomResourceUuid types.UUID
+	LabelUuid          types.UUID
+}
+
+type CustomResourceAnnotation struct {
+	CustomResourceUuid types.UUID
+	AnnotationUuid     types.UUID
+}
+
+// NewCustomResourceFactory returns a function that creates custom resources of the given API resource.
+func NewCustomResourceFactory(gvr kschema.GroupVersionResource) func() Resource {
+	return func() Resource {
+		return &CustomResource{
+			ApiGroup:   gvr.Group,
+			ApiVersion: gvr.Version,
+			Resource:   gvr.Resource,
+		}
+	}
+}
+
+func (c *CustomResource) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
+	c.ObtainMeta(k8s, clusterUuid)
+
+	resource := k8s.(*unstructured.Unstructured)
+
+	c.Kind = resource.GetKind()
+
+	conditions, _, _ := unstructured.NestedSlice(resource.Object, "status", "conditions")
+	seen := make(map[string]struct{}, len(conditions))
+	for _, condition := range conditions {
+		condition, ok := condition.(map[string]interface{})
+		if !ok {
+			continue
+		}
+
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		if _, ok := seen[conditionType]; ok || conditionType == "" {
+			continue
+		}
+		seen[conditionType] = struct{}{}
+
+		status, _, _ := unstructured.NestedString(condition, "status")
+		reason, _, _ := unstructured.NestedString(condition, "reason")
+		message, _, _ := unstructured.NestedString(condition, "message")
+		lastTransition, _, _ := unstructured.NestedString(condition, "lastTransitionTime")
+		// Conditions that do not follow the conventions simply have no last transition.
+		lastTransitionTime, _ := time.Parse(time.RFC3339, lastTransition)
+
+		c.Conditions = append(c.Conditions, CustomResourceCondition{
+			CustomResourceUuid: c.Uuid,
+			Type:               conditionType,
+			Status:             strcase.Snake(status),
+			LastTransition:     types.UnixMilli(lastTransitionTime),
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	c.IcingaState, c.IcingaStateReason = c.getIcingaState()
+
+	for labelName, labelValue := range resource.GetLabels() {
+		labelUuid := NewUUID(c.Uuid, strings.ToLower(labelName+":"+labelValue))
+		c.Labels = append(c.Labels, Label{
+			Uuid:  labelUuid,
+			Name:  labelName,
+			Value: labelValue,
+		})
+		c.CustomResourceLabels = append(c.CustomResourceLabels, CustomResourceLabel{
+			CustomResourceUuid: c.Uuid,
+			LabelUuid:          labelUuid,
+		})
+		c.ResourceLabels = append(c.ResourceLabels, ResourceLabel{
+			ResourceUuid: c.Uuid,
+			LabelUuid:    labelUuid,
+		})
+	}
+
+	for annotationName, annotationValue := range resource.GetAnnotations() {
+		annotationUuid := NewUUID(c.Uuid, strings.ToLower(annotationName+":"+annotationValue))
+		c.Annotations = append(c.Annotations, Annotation{
+			Uuid:  annotationUuid,
+			Name:  annotationName,
+			Value: annotationValue,
+		})
+		c.CustomResourceAnnotations = append(c.CustomResourceAnnotations, CustomResourceAnnotation{
+			CustomResourceUuid: c.Uuid,
+			AnnotationUuid:     annotationUuid,
+		})
+		c.ResourceAnnotations = append(c.ResourceAnnotations, ResourceAnnotation{
+			ResourceUuid:   c.Uuid,
+			AnnotationUuid: annotationUuid,
+		})
+	}
+
+	output, _ := kruntime.Encode(kjson.NewYAMLSerializer(kjson.DefaultMetaFactory, nil, nil), resource)
+	c.Yaml = string(output)
+}
+
+func (c *CustomResource) MarshalEvent() (notifications.Event, error) {
+	return notifications.Event{
+		Uuid:        c.Uuid,
+		ClusterUuid: c.ClusterUuid,
+		Kind:        "custom_resource",
+		Name:        c.objectName(),
+		Severity:    c.IcingaState.ToSeverity(),
+		Message:     c.IcingaStateReason,
+		URL:         &url.URL{Path: "/customresource", RawQuery: fmt.Sprintf("id=%s", c.Uuid)},
+		Tags: map[string]string{
+			"uuid":         c.Uuid.String(),
+			"cluster_uuid": c.ClusterUuid.String(),
+			"name":         c.Name,
+			"namespace":    c.Namespace,
+			"resource":     "custom_resource",
+			"api_group":    c.ApiGroup,
+			"kind":         c.Kind,
+		},
+		Labels:      labelMap(c.Labels),
+		Annotations: annotationMap(c.Annotations),
+	}, nil
+}
+
+// getIcingaState derives the state from the Ready or, if there is none, the Available condition,
+// which most APIs provide by convention.
+func (c *CustomResource) getIcingaState() (IcingaState, string) {
+	var condition *CustomResourceCondition
+	for _, conditionType := range []string{"Ready", "Available"} {
+		for i := range c.Conditions {
+			if c.Conditions[i].Type == conditionType {
+				condition = &c.Conditions[i]
+
+				break
+			}
+		}
+
+		if condition != nil {
+			break
+		}
+	}
+
+	if condition == nil {
+		reason := fmt.Sprintf("%s %s has neither a Ready nor an Available condition.", c.Kind, c.objectName())
+
+		return Unknown, reason
+	}
+
+	switch condition.Status {
+	case "true":
+		reason := fmt.Sprintf("%s %s is %s.", c.Kind, c.objectName(), strings.ToLower(condition.Type))
+
+		return Ok, reason
+	case "false":
+		reason := fmt.Sprintf(
+			"%s %s is not %s: %s.", c.Kind, c.objectName(), strings.ToLower(condition.Type), conditionDetails(condition))
+
+		return Critical, reason
+	default:
+		reason := fmt.Sprintf(
+			"%s %s is pending to become %s: %s.",
+			c.Kind, c.objectName(), strings.ToLower(condition.Type), conditionDetails(condition))
+
+		return Pending, reason
+	}
+}
+
+// objectName returns the name of the resource qualified with its namespace, if any.
+func (c *CustomResource) objectName() string {
+	if c.Namespace == "" {
+		return c.Name
+	}
+
+	return c.Namespace + "/" + c.Name
+}
+
+func (c *CustomResource) Relations() []database.Relation {
+	fk := database.WithForeignKey("custom_resource_uuid")
+
+	return []database.Relation{
+		database.HasMany(c.Conditions, fk),
+		database.HasMany(c.ResourceLabels, database.WithForeignKey("resource_uuid")),
+		database.HasMany(c.Labels, database.WithoutCascadeDelete()),
+		database.HasMany(c.CustomResourceLabels, fk),
+		database.HasMany(c.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
+		database.HasMany(c.Annotations, database.WithoutCascadeDelete()),
+		database.HasMany(c.CustomResourceAnnotations, fk),
+	}
+}
+
+// conditionDetails returns the message of the given condition or its reason if there is no message.
+func conditionDetails(condition *CustomResourceCondition) string {
+	if condition.Message != "" {
+		return condition.Message
+	}
+
+	if condition.Reason != "" {
+		return condition.Reason
+	}
+
+	return "no reason given"
+}
@@ -95,7 +95,7 @@ func (d *DaemonSet) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		d.Conditions = append(d.Conditions, DaemonSetCondition{
 			DaemonSetUuid:  d.Uuid,
 			Type:           string(condition.Type),
-			Status:         string(condition.Status),
+			Status:         strcase.Snake(string(condition.Status)),
 			LastTransition: types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:         condition.Reason,
 			Message:        condition.Message,
@@ -184,6 +184,8 @@ func (d *DaemonSet) MarshalEvent() (notifications.Event, error) {
 			"namespace":    d.Namespace,
 			"resource":     "daemon_set",
 		},
+		Labels:      labelMap(d.Labels),
+		Annotations: annotationMap(d.Annotations),
 	}, nil
 }
 
@@ -222,6 +224,6 @@ func (d *DaemonSet) Relations() []database.Relation {
 		database.HasMany(d.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(d.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(d.DaemonSetAnnotations, fk),
-		database.HasMany(d.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(d.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
@@ -106,7 +106,7 @@ func (d *Deployment) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		d.Conditions = append(d.Conditions, DeploymentCondition{
 			DeploymentUuid: d.Uuid,
 			Type:           string(condition.Type),
-			Status:         string(condition.Status),
+			Status:         strcase.Snake(string(condition.Status)),
 			LastUpdate:     types.UnixMilli(condition.LastUpdateTime.Time),
 			LastTransition: types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:         condition.Reason,
@@ -196,17 +196,19 @@ func (d *Deployment) MarshalEvent() (notifications.Event, error) {
 			"namespace":    d.Namespace,
 			"resource":     "deployment",
 		},
+		Labels:      labelMap(d.Labels),
+		Annotations: annotationMap(d.Annotations),
 	}, nil
 }
 
 func (d *Deployment) getIcingaState() (IcingaState, string) {
 	for _, condition := range d.Conditions {
-		if condition.Type == string(kappsv1.DeploymentAvailable) && condition.Status != string(kcorev1.ConditionTrue) {
+		if condition.Type == string(kappsv1.DeploymentAvailable) && condition.Status != strcase.Snake(string(kcorev1.ConditionTrue)) {
 			reason := fmt.Sprintf("Deployment %s/%s is not available: %s.", d.Namespace, d.Name, condition.Message)
 
 			return Critical, reason
 		}
-		if condition.Type == string(kappsv1.ReplicaSetReplicaFailure) && condition.Status != string(kcorev1.ConditionTrue) {
+		if condition.Type == string(kappsv1.ReplicaSetReplicaFailure) && condition.Status != strcase.Snake(string(kcorev1.ConditionTrue)) {
 			reason := fmt.Sprintf("Deployment %s/%s has replica failure: %s.", d.Namespace, d.Name, condition.Message)
 
 			return Critical, reason
@@ -241,6 +243,6 @@ func (d *Deployment) Relations() []database.Relation {
 		database.HasMany(d.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(d.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(d.DeploymentAnnotations, fk),
-		database.HasMany(d.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(d.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
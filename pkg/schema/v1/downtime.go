@@ -0,0 +1,21 @@
+package v1
+
+import (
+	"database/sql"
+	"github.com/icinga/icinga-go-library/types"
+)
+
+// Downtime suppresses the notifications of the resources it covers for a time range.
+// It covers the whole cluster unless restricted to a namespace, a label selector or a single resource,
+// which are combined if multiple are set. Downtimes defined in the configuration file are locked.
+type Downtime struct {
+	Uuid          types.UUID
+	ClusterUuid   types.UUID
+	Namespace     sql.NullString
+	LabelSelector sql.NullString
+	ResourceUuid  types.Binary
+	StartTime     types.UnixMilli
+	EndTime       types.UnixMilli
+	Comment       string
+	Locked        types.Bool
+}
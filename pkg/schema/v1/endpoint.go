@@ -2,6 +2,7 @@ package v1
 
 import (
 	"database/sql"
+	"github.com/icinga/icinga-go-library/strcase"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
 	v1 "k8s.io/api/core/v1"
@@ -134,7 +135,7 @@ func (e *EndpointSlice) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		}
 		var kind sql.NullString
 		if targetRef.Kind != "" {
-			kind.String = targetRef.Kind
+			kind.String = strcase.Snake(targetRef.Kind)
 			kind.Valid = true
 		}
 		e.EndpointTargetRefs = append(e.EndpointTargetRefs, EndpointTargetRef{
@@ -229,6 +229,6 @@ func (i *Ingress) Relations() []database.Relation {
 		database.HasMany(i.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(i.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(i.IngressAnnotations, fk),
-		database.HasMany(i.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(i.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
@@ -5,6 +5,13 @@ import (
 	"github.com/icinga/icinga-go-library/types"
 )
 
+// Roles of an instance if leader election is enabled.
+// Only the leader synchronizes, all other instances are on standby.
+const (
+	InstanceRoleLeader  = "leader"
+	InstanceRoleStandby = "standby"
+)
+
 type Instance struct {
 	Uuid                   types.Binary
 	ClusterUuid            types.UUID
@@ -12,6 +19,7 @@ type Instance struct {
 	KubernetesVersion      sql.NullString
 	KubernetesHeartbeat    types.UnixMilli
 	KubernetesApiReachable types.Bool
+	Role                   string
 	Message                sql.NullString
 	Heartbeat              types.UnixMilli
 }
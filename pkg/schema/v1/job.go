@@ -6,6 +6,7 @@ import (
 	"github.com/icinga/icinga-go-library/strcase"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
+	"github.com/icinga/icinga-kubernetes/pkg/notifications"
 	kbatchv1 "k8s.io/api/batch/v1"
 	kcorev1 "k8s.io/api/core/v1"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -13,6 +14,7 @@ import (
 	kserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
 	ktypes "k8s.io/apimachinery/pkg/types"
+	"net/url"
 	"strings"
 )
 
@@ -145,7 +147,7 @@ func (j *Job) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		j.Conditions = append(j.Conditions, JobCondition{
 			JobUuid:        j.Uuid,
 			Type:           string(condition.Type),
-			Status:         string(condition.Status),
+			Status:         strcase.Snake(string(condition.Status)),
 			LastProbe:      types.UnixMilli(condition.LastProbeTime.Time),
 			LastTransition: types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:         condition.Reason,
@@ -219,6 +221,27 @@ func (j *Job) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 	j.Yaml = string(output)
 }
 
+func (j *Job) MarshalEvent() (notifications.Event, error) {
+	return notifications.Event{
+		Uuid:        j.Uuid,
+		ClusterUuid: j.ClusterUuid,
+		Kind:        "job",
+		Name:        j.Namespace + "/" + j.Name,
+		Severity:    j.IcingaState.ToSeverity(),
+		Message:     j.IcingaStateReason,
+		URL:         &url.URL{Path: "/job", RawQuery: fmt.Sprintf("id=%s", j.Uuid)},
+		Tags: map[string]string{
+			"uuid":         j.Uuid.String(),
+			"cluster_uuid": j.ClusterUuid.String(),
+			"name":         j.Name,
+			"namespace":    j.Namespace,
+			"resource":     "job",
+		},
+		Labels:      labelMap(j.Labels),
+		Annotations: annotationMap(j.Annotations),
+	}, nil
+}
+
 func (j *Job) getIcingaState(job *kbatchv1.Job) (IcingaState, string) {
 	for _, condition := range job.Status.Conditions {
 		if condition.Status != kcorev1.ConditionTrue {
@@ -288,6 +311,6 @@ func (j *Job) Relations() []database.Relation {
 		database.HasMany(j.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(j.JobAnnotations, fk),
 		database.HasMany(j.Owners, fk),
-		database.HasMany(j.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(j.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
@@ -9,3 +9,13 @@ type Label struct {
 	Name  string
 	Value string
 }
+
+// labelMap returns the given labels as a map of names to values.
+func labelMap(labels []Label) map[string]string {
+	m := make(map[string]string, len(labels))
+	for _, label := range labels {
+		m[label.Name] = label.Value
+	}
+
+	return m
+}
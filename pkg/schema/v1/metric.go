@@ -86,6 +86,27 @@ func (m *PrometheusContainerMetric) Fingerprint() database.Fingerprinter {
 	return m
 }
 
+// PvcMetric is a storage usage metric of a PVC as reported by the kubelet.
+type PvcMetric struct {
+	PvcUuid   types.UUID
+	Timestamp int64
+	Category  string
+	Name      string
+	Value     float64
+}
+
+func (m *PvcMetric) ID() database.ID {
+	return compoundId{id: m.PvcUuid.String() + strconv.FormatInt(m.Timestamp, 10) + m.Category + m.Name}
+}
+
+func (m *PvcMetric) SetID(id database.ID) {
+	panic("Not expected to be called")
+}
+
+func (m *PvcMetric) Fingerprint() database.Fingerprinter {
+	return m
+}
+
 type compoundId struct {
 	id string
 }
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"github.com/icinga/icinga-go-library/strcase"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
 	kcorev1 "k8s.io/api/core/v1"
@@ -59,7 +60,7 @@ func (n *Namespace) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		n.Conditions = append(n.Conditions, NamespaceCondition{
 			NamespaceUuid:  n.Uuid,
 			Type:           string(condition.Type),
-			Status:         string(condition.Status),
+			Status:         strcase.Snake(string(condition.Status)),
 			LastTransition: types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:         condition.Reason,
 			Message:        condition.Message,
@@ -118,6 +119,6 @@ func (n *Namespace) Relations() []database.Relation {
 		database.HasMany(n.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(n.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(n.NamespaceAnnotations, fk),
-		database.HasMany(n.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(n.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/icinga/icinga-go-library/strcase"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
 	"github.com/icinga/icinga-kubernetes/pkg/notifications"
@@ -137,7 +138,7 @@ func (n *Node) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		n.Conditions = append(n.Conditions, NodeCondition{
 			NodeUuid:       n.Uuid,
 			Type:           string(condition.Type),
-			Status:         string(condition.Status),
+			Status:         strcase.Snake(string(condition.Status)),
 			LastHeartbeat:  types.UnixMilli(condition.LastHeartbeatTime.Time),
 			LastTransition: types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:         condition.Reason,
@@ -220,6 +221,8 @@ func (n *Node) MarshalEvent() (notifications.Event, error) {
 			"namespace":    n.Namespace,
 			"resource":     "node",
 		},
+		Labels:      labelMap(n.Labels),
+		Annotations: annotationMap(n.Annotations),
 	}, nil
 }
 
@@ -288,7 +291,7 @@ func (n *Node) Relations() []database.Relation {
 		database.HasMany(n.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(n.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(n.NodeAnnotations, fk),
-		database.HasMany(n.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(n.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
 
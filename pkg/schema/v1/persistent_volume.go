@@ -2,14 +2,17 @@ package v1
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
+	"github.com/icinga/icinga-kubernetes/pkg/notifications"
 	kcorev1 "k8s.io/api/core/v1"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kruntime "k8s.io/apimachinery/pkg/runtime"
 	kserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
 	ktypes "k8s.io/apimachinery/pkg/types"
+	"net/url"
 	"strings"
 )
 
@@ -25,6 +28,8 @@ type PersistentVolume struct {
 	Phase                       string
 	Reason                      sql.NullString
 	Message                     sql.NullString
+	IcingaState                 IcingaState
+	IcingaStateReason           string
 	Yaml                        string
 	Claim                       *PersistentVolumeClaimRef    `db:"-"`
 	Labels                      []Label                      `db:"-"`
@@ -83,6 +88,8 @@ func (p *PersistentVolume) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		panic(err)
 	}
 
+	p.IcingaState, p.IcingaStateReason = p.getIcingaState(persistentVolume)
+
 	if persistentVolume.Spec.ClaimRef != nil {
 		p.Claim = &PersistentVolumeClaimRef{
 			PersistentVolumeUuid: p.Uuid,
@@ -133,6 +140,50 @@ func (p *PersistentVolume) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 	p.Yaml = string(output)
 }
 
+func (p *PersistentVolume) MarshalEvent() (notifications.Event, error) {
+	return notifications.Event{
+		Uuid:        p.Uuid,
+		ClusterUuid: p.ClusterUuid,
+		Kind:        "persistent_volume",
+		Name:        p.Name,
+		Severity:    p.IcingaState.ToSeverity(),
+		Message:     p.IcingaStateReason,
+		URL:         &url.URL{Path: "/persistentvolume", RawQuery: fmt.Sprintf("id=%s", p.Uuid)},
+		Tags: map[string]string{
+			"uuid":         p.Uuid.String(),
+			"cluster_uuid": p.ClusterUuid.String(),
+			"name":         p.Name,
+			"namespace":    p.Namespace,
+			"resource":     "persistent_volume",
+		},
+		Labels:      labelMap(p.Labels),
+		Annotations: annotationMap(p.Annotations),
+	}, nil
+}
+
+func (p *PersistentVolume) getIcingaState(persistentVolume *kcorev1.PersistentVolume) (IcingaState, string) {
+	switch persistentVolume.Status.Phase {
+	case kcorev1.VolumeFailed:
+		return Critical, fmt.Sprintf(
+			"PersistentVolume %s failed its automatic reclamation. %s: %s.",
+			p.Name, persistentVolume.Status.Reason, persistentVolume.Status.Message)
+	case kcorev1.VolumeReleased:
+		return Warning, fmt.Sprintf(
+			"PersistentVolume %s has been released by its claim but not yet reclaimed. Reclaim policy is %s.",
+			p.Name, p.ReclaimPolicy)
+	case kcorev1.VolumePending:
+		return Pending, fmt.Sprintf("PersistentVolume %s is not yet available.", p.Name)
+	case kcorev1.VolumeBound:
+		if claim := persistentVolume.Spec.ClaimRef; claim != nil {
+			return Ok, fmt.Sprintf("PersistentVolume %s is bound to claim %s/%s.", p.Name, claim.Namespace, claim.Name)
+		}
+
+		return Ok, fmt.Sprintf("PersistentVolume %s is bound.", p.Name)
+	default:
+		return Ok, fmt.Sprintf("PersistentVolume %s is available.", p.Name)
+	}
+}
+
 func (p *PersistentVolume) Relations() []database.Relation {
 	if p.Claim == nil {
 		return []database.Relation{}
@@ -148,6 +199,6 @@ func (p *PersistentVolume) Relations() []database.Relation {
 		database.HasMany(p.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(p.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(p.PersistentVolumeAnnotations, fk),
-		database.HasMany(p.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(p.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
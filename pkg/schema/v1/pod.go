@@ -137,7 +137,7 @@ func (p *Pod) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		p.Conditions = append(p.Conditions, PodCondition{
 			PodUuid:        p.Uuid,
 			Type:           string(condition.Type),
-			Status:         string(condition.Status),
+			Status:         strcase.Snake(string(condition.Status)),
 			LastProbe:      types.UnixMilli(condition.LastProbeTime.Time),
 			LastTransition: types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:         condition.Reason,
@@ -306,9 +306,22 @@ func (p *Pod) MarshalEvent() (notifications.Event, error) {
 			"namespace":    p.Namespace,
 			"resource":     "pod",
 		},
+		ExtraTags:   nodeExtraTags(p.NodeName),
+		Labels:      labelMap(p.Labels),
+		Annotations: annotationMap(p.Annotations),
 	}, nil
 }
 
+// nodeExtraTags returns the extra tags of events of pods and their containers
+// naming the node the pod is scheduled on, if any.
+func nodeExtraTags(nodeName sql.NullString) map[string]string {
+	if !nodeName.Valid || nodeName.String == "" {
+		return nil
+	}
+
+	return map[string]string{"node": nodeName.String}
+}
+
 func (p *Pod) getIcingaState(pod *kcorev1.Pod) (IcingaState, string) {
 	if pod.Status.Reason == "NodeLost" {
 		return Unknown, fmt.Sprintf(
@@ -377,6 +390,11 @@ func (p *Pod) getIcingaState(pod *kcorev1.Pod) (IcingaState, string) {
 
 	state, reasons, notRunning := collectContainerStates(p)
 
+	if storageState, storageReasons := collectEphemeralStorageStates(pod, p.Uuid); storageState != Ok {
+		state = max(state, storageState)
+		reasons = storageReasons + "\n" + reasons
+	}
+
 	return state, fmt.Sprintf(
 		"Pod %s/%s is %s with %d out of %d containers running.\n%s",
 		pod.Namespace,
@@ -388,6 +406,30 @@ func (p *Pod) getIcingaState(pod *kcorev1.Pod) (IcingaState, string) {
 	)
 }
 
+// collectEphemeralStorageStates returns the worst Icinga state and the reasons of the containers of the given pod
+// whose ephemeral storage usage exceeds the thresholds in relation to their ephemeral storage limit.
+func collectEphemeralStorageStates(pod *kcorev1.Pod, podUuid types.UUID) (IcingaState, string) {
+	state := Ok
+	var reasons []string
+
+	for _, container := range pod.Spec.Containers {
+		usage, ok := GetStorageUsage(NewUUID(podUuid, container.Name))
+		if !ok {
+			continue
+		}
+
+		if containerState := storageState(usage); containerState != Ok {
+			state = max(state, containerState)
+			reasons = append(reasons, fmt.Sprintf(
+				"[%s] Container %s uses %.1f%% of its ephemeral storage limit with %s of %s.",
+				strings.ToUpper(containerState.String()), container.Name, usage.Fraction()*100,
+				formatBytes(usage.Used), formatBytes(usage.Capacity)))
+		}
+	}
+
+	return state, strings.Join(reasons, "\n")
+}
+
 func NewContainers[T any](
 	p *Pod,
 	containers []kcorev1.Container,
@@ -427,7 +469,7 @@ func (p *Pod) Relations() []database.Relation {
 		database.HasMany(p.PodAnnotations, fk),
 		database.HasMany(p.Pvcs, fk),
 		database.HasMany(p.Volumes, fk),
-		database.HasMany(p.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(p.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
 
@@ -2,14 +2,17 @@ package v1
 
 import (
 	"database/sql"
+	"fmt"
 	"github.com/icinga/icinga-go-library/strcase"
 	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
+	"github.com/icinga/icinga-kubernetes/pkg/notifications"
 	kcorev1 "k8s.io/api/core/v1"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kruntime "k8s.io/apimachinery/pkg/runtime"
 	kserializer "k8s.io/apimachinery/pkg/runtime/serializer"
 	kjson "k8s.io/apimachinery/pkg/runtime/serializer/json"
+	"net/url"
 	"strings"
 )
 
@@ -44,6 +47,8 @@ type Pvc struct {
 	VolumeName          sql.NullString
 	VolumeMode          string
 	StorageClass        sql.NullString
+	IcingaState         IcingaState
+	IcingaStateReason   string
 	Yaml                string
 	Conditions          []PvcCondition       `db:"-"`
 	Labels              []Label              `db:"-"`
@@ -113,7 +118,7 @@ func (p *Pvc) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		p.Conditions = append(p.Conditions, PvcCondition{
 			PvcUuid:        p.Uuid,
 			Type:           strcase.Snake(string(condition.Type)),
-			Status:         string(condition.Status),
+			Status:         strcase.Snake(string(condition.Status)),
 			LastProbe:      types.UnixMilli(condition.LastProbeTime.Time),
 			LastTransition: types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:         condition.Reason,
@@ -121,6 +126,8 @@ func (p *Pvc) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		})
 	}
 
+	p.IcingaState, p.IcingaStateReason = p.getIcingaState(pvc)
+
 	for labelName, labelValue := range pvc.Labels {
 		labelUuid := NewUUID(p.Uuid, strings.ToLower(labelName+":"+labelValue))
 		p.Labels = append(p.Labels, Label{
@@ -162,6 +169,66 @@ func (p *Pvc) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 	p.Yaml = string(output)
 }
 
+func (p *Pvc) MarshalEvent() (notifications.Event, error) {
+	return notifications.Event{
+		Uuid:        p.Uuid,
+		ClusterUuid: p.ClusterUuid,
+		Kind:        "pvc",
+		Name:        p.Namespace + "/" + p.Name,
+		Severity:    p.IcingaState.ToSeverity(),
+		Message:     p.IcingaStateReason,
+		URL:         &url.URL{Path: "/pvc", RawQuery: fmt.Sprintf("id=%s", p.Uuid)},
+		Tags: map[string]string{
+			"uuid":         p.Uuid.String(),
+			"cluster_uuid": p.ClusterUuid.String(),
+			"name":         p.Name,
+			"namespace":    p.Namespace,
+			"resource":     "pvc",
+		},
+		Labels:      labelMap(p.Labels),
+		Annotations: annotationMap(p.Annotations),
+	}, nil
+}
+
+func (p *Pvc) getIcingaState(pvc *kcorev1.PersistentVolumeClaim) (IcingaState, string) {
+	switch pvc.Status.Phase {
+	case kcorev1.ClaimLost:
+		return Critical, fmt.Sprintf(
+			"PVC %s/%s lost its underlying volume %s.", p.Namespace, p.Name, pvc.Spec.VolumeName)
+	case kcorev1.ClaimPending:
+		return Pending, fmt.Sprintf("PVC %s/%s is not yet bound to a volume.", p.Namespace, p.Name)
+	}
+
+	resizing := false
+	for _, condition := range pvc.Status.Conditions {
+		if condition.Status != kcorev1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case kcorev1.PersistentVolumeClaimControllerResizeError, kcorev1.PersistentVolumeClaimNodeResizeError:
+			return Warning, fmt.Sprintf(
+				"PVC %s/%s cannot be resized. %s: %s.", p.Namespace, p.Name, condition.Reason, condition.Message)
+		case kcorev1.PersistentVolumeClaimResizing, kcorev1.PersistentVolumeClaimFileSystemResizePending:
+			resizing = true
+		}
+	}
+
+	if usage, ok := GetStorageUsage(p.Uuid); ok {
+		if state := storageState(usage); state != Ok {
+			return state, fmt.Sprintf("PVC %s/%s is %.1f%% full with %s of %s used.",
+				p.Namespace, p.Name, usage.Fraction()*100, formatBytes(usage.Used), formatBytes(usage.Capacity))
+		}
+	}
+
+	if resizing {
+		return Ok, fmt.Sprintf("PVC %s/%s is bound to volume %s and being resized.",
+			p.Namespace, p.Name, pvc.Spec.VolumeName)
+	}
+
+	return Ok, fmt.Sprintf("PVC %s/%s is bound to volume %s.", p.Namespace, p.Name, pvc.Spec.VolumeName)
+}
+
 func (p *Pvc) Relations() []database.Relation {
 	fk := database.WithForeignKey("pvc_uuid")
 
@@ -173,6 +240,6 @@ func (p *Pvc) Relations() []database.Relation {
 		database.HasMany(p.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(p.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(p.PvcAnnotations, fk),
-		database.HasMany(p.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(p.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
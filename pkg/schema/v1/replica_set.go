@@ -94,7 +94,7 @@ func (r *ReplicaSet) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		r.Conditions = append(r.Conditions, ReplicaSetCondition{
 			ReplicaSetUuid: r.Uuid,
 			Type:           string(condition.Type),
-			Status:         string(condition.Status),
+			Status:         strcase.Snake(string(condition.Status)),
 			LastTransition: types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:         condition.Reason,
 			Message:        condition.Message,
@@ -183,12 +183,14 @@ func (r *ReplicaSet) MarshalEvent() (notifications.Event, error) {
 			"namespace":    r.Namespace,
 			"resource":     "replica_set",
 		},
+		Labels:      labelMap(r.Labels),
+		Annotations: annotationMap(r.Annotations),
 	}, nil
 }
 
 func (r *ReplicaSet) getIcingaState() (IcingaState, string) {
 	for _, condition := range r.Conditions {
-		if condition.Type == string(kappsv1.ReplicaSetReplicaFailure) && condition.Status == string(kcorev1.ConditionTrue) {
+		if condition.Type == string(kappsv1.ReplicaSetReplicaFailure) && condition.Status == strcase.Snake(string(kcorev1.ConditionTrue)) {
 			reason := fmt.Sprintf("ReplicaSet %s/%s has a failure condition: %s.", r.Namespace, r.Name, condition.Message)
 
 			return Critical, reason
@@ -223,6 +225,6 @@ func (r *ReplicaSet) Relations() []database.Relation {
 		database.HasMany(r.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(r.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(r.ReplicaSetAnnotations, fk),
-		database.HasMany(r.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(r.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
@@ -119,7 +119,7 @@ func (s *StatefulSet) Obtain(k8s kmetav1.Object, clusterUuid types.UUID) {
 		s.Conditions = append(s.Conditions, StatefulSetCondition{
 			StatefulSetUuid: s.Uuid,
 			Type:            string(condition.Type),
-			Status:          string(condition.Status),
+			Status:          strcase.Snake(string(condition.Status)),
 			LastTransition:  types.UnixMilli(condition.LastTransitionTime.Time),
 			Reason:          condition.Reason,
 			Message:         condition.Message,
@@ -208,6 +208,8 @@ func (s *StatefulSet) MarshalEvent() (notifications.Event, error) {
 			"namespace":    s.Namespace,
 			"resource":     "stateful_set",
 		},
+		Labels:      labelMap(s.Labels),
+		Annotations: annotationMap(s.Annotations),
 	}, nil
 }
 
@@ -240,6 +242,6 @@ func (s *StatefulSet) Relations() []database.Relation {
 		database.HasMany(s.ResourceAnnotations, database.WithForeignKey("resource_uuid")),
 		database.HasMany(s.Annotations, database.WithoutCascadeDelete()),
 		database.HasMany(s.StatefulSetAnnotations, fk),
-		database.HasMany(s.Favorites, database.WithForeignKey("resource_uuid")),
+		database.HasMany(s.Favorites, database.WithForeignKey("resource_uuid"), database.WithoutStaleDelete()),
 	}
 }
@@ -0,0 +1,95 @@
+package v1
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/icinga/icinga-go-library/types"
+)
+
+// StorageUsage is the used and available storage of a PVC or container as reported by the kubelet.
+type StorageUsage struct {
+	Used     int64
+	Capacity int64
+}
+
+// Fraction returns the used storage in relation to the capacity, or 0 if the capacity is unknown.
+func (u StorageUsage) Fraction() float64 {
+	if u.Capacity <= 0 {
+		return 0
+	}
+
+	return float64(u.Used) / float64(u.Capacity)
+}
+
+// storageUsages holds the latest StorageUsage per PVC UUID and container UUID.
+var storageUsages sync.Map
+
+// Storage usage thresholds as fractions of the capacity, see SetStorageThresholds.
+var (
+	storageWarning  = 0.8
+	storageCritical = 0.9
+)
+
+// SetStorageThresholds sets the fractions of the capacity from which the storage usage of PVCs and containers
+// leads to a warning or critical Icinga state. It must be called before any resource is synchronized.
+func SetStorageThresholds(warning, critical float64) {
+	storageWarning, storageCritical = warning, critical
+}
+
+// SetStorageUsage stores the storage usage of the PVC or container with the given UUID and
+// returns whether its Icinga state changed as a result.
+func SetStorageUsage(id types.UUID, usage StorageUsage) bool {
+	previous, loaded := storageUsages.Swap(id, usage)
+	if !loaded {
+		return storageState(usage) != Ok
+	}
+
+	return storageState(previous.(StorageUsage)) != storageState(usage)
+}
+
+// GetStorageUsage returns the storage usage of the PVC or container with the given UUID, if known.
+func GetStorageUsage(id types.UUID) (StorageUsage, bool) {
+	usage, ok := storageUsages.Load(id)
+	if !ok {
+		return StorageUsage{}, false
+	}
+
+	return usage.(StorageUsage), true
+}
+
+// ForgetStorageUsage removes the storage usage of the PVC or container with the given UUID and
+// returns whether its Icinga state changed as a result.
+func ForgetStorageUsage(id types.UUID) bool {
+	previous, loaded := storageUsages.LoadAndDelete(id)
+
+	return loaded && storageState(previous.(StorageUsage)) != Ok
+}
+
+// storageState returns the Icinga state of the given storage usage according to the thresholds.
+func storageState(usage StorageUsage) IcingaState {
+	switch fraction := usage.Fraction(); {
+	case fraction >= storageCritical:
+		return Critical
+	case fraction >= storageWarning:
+		return Warning
+	default:
+		return Ok
+	}
+}
+
+// formatBytes formats the given number of bytes using binary prefixes.
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%dB", b)
+	}
+
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(b)/float64(div), "KMGTPE"[exp])
+}
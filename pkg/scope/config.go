@@ -0,0 +1,70 @@
+package scope
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Resources lists the names of all resources whose scope can be configured.
+var Resources = []string{
+	"configmaps",
+	"cronjobs",
+	"daemonsets",
+	"deployments",
+	"endpointslices",
+	"events",
+	"ingresses",
+	"jobs",
+	"namespaces",
+	"nodes",
+	"persistentvolumeclaims",
+	"persistentvolumes",
+	"pods",
+	"replicasets",
+	"secrets",
+	"services",
+	"statefulsets",
+}
+
+// Config defines which namespaces and resources are synchronized.
+type Config struct {
+	// If Namespaces is empty, all namespaces except ExcludeNamespaces are synchronized.
+	Namespaces        []string `yaml:"namespaces" env:"NAMESPACES"`
+	ExcludeNamespaces []string `yaml:"exclude_namespaces" env:"EXCLUDE_NAMESPACES"`
+	// Selectors maps resource names, see Resources, to the selectors applied to resources of that kind.
+	// Custom resources are referred to by their group-qualified resource name, e.g. certificates.cert-manager.io.
+	Selectors map[string]Selectors `yaml:"selectors"`
+}
+
+// Selectors defines the label and field selector used to list and watch resources of a kind.
+type Selectors struct {
+	Label string `yaml:"label"`
+	Field string `yaml:"field"`
+}
+
+// Validate checks constraints in the supplied scope configuration and returns an error if they are violated.
+func (c *Config) Validate() error {
+	if len(c.Namespaces) > 0 && len(c.ExcludeNamespaces) > 0 {
+		return errors.New("'namespaces' and 'exclude_namespaces' are mutually exclusive")
+	}
+
+	for resource, selectors := range c.Selectors {
+		if !slices.Contains(Resources, resource) && !strings.Contains(resource, ".") {
+			return errors.Errorf("unknown resource %q in 'selectors'", resource)
+		}
+
+		if _, err := labels.Parse(selectors.Label); err != nil {
+			return errors.Wrapf(err, "invalid label selector for %q", resource)
+		}
+
+		if _, err := fields.ParseSelector(selectors.Field); err != nil {
+			return errors.Wrapf(err, "invalid field selector for %q", resource)
+		}
+	}
+
+	return nil
+}
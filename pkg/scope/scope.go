@@ -0,0 +1,259 @@
+package scope
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kschema "k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+)
+
+// clusterScoped lists the resources that do not belong to a namespace.
+var clusterScoped = []string{"namespaces", "nodes", "persistentvolumes"}
+
+// Scope restricts the resources to synchronize to the configured namespaces and selectors.
+// Resources are listed and watched with one informer factory per resource and namespace,
+// so that the selectors of one resource do not affect other resources.
+type Scope struct {
+	config        *Config
+	clientset     kubernetes.Interface
+	dynamicClient dynamic.Interface
+
+	mu               sync.Mutex
+	factories        map[string]informers.SharedInformerFactory
+	dynamicFactories map[string]dynamicinformer.DynamicSharedInformerFactory
+}
+
+// NewScope creates a new Scope for the given configuration.
+func NewScope(clientset kubernetes.Interface, dynamicClient dynamic.Interface, c *Config) *Scope {
+	return &Scope{
+		config:           c,
+		clientset:        clientset,
+		dynamicClient:    dynamicClient,
+		factories:        make(map[string]informers.SharedInformerFactory),
+		dynamicFactories: make(map[string]dynamicinformer.DynamicSharedInformerFactory),
+	}
+}
+
+// Namespaces returns the namespaces to watch separately.
+// Unless namespaces are included explicitly, this is only kmetav1.NamespaceAll.
+func (s *Scope) Namespaces() []string {
+	if len(s.config.Namespaces) > 0 {
+		return s.config.Namespaces
+	}
+
+	return []string{kmetav1.NamespaceAll}
+}
+
+// NamespacesOf returns the namespaces to watch separately for the given resource,
+// which is only kmetav1.NamespaceAll for cluster-scoped resources other than namespaces.
+func (s *Scope) NamespacesOf(resource string) []string {
+	if slices.Contains(clusterScoped, resource) && resource != "namespaces" {
+		return []string{kmetav1.NamespaceAll}
+	}
+
+	return s.Namespaces()
+}
+
+// InNamespace returns whether resources of the given namespace are in scope.
+func (s *Scope) InNamespace(namespace string) bool {
+	if len(s.config.Namespaces) > 0 {
+		return slices.Contains(s.config.Namespaces, namespace)
+	}
+
+	return !slices.Contains(s.config.ExcludeNamespaces, namespace)
+}
+
+// Factory returns the informer factory for the given resource in the given namespace,
+// which must be one of Namespaces. For cluster-scoped resources other than namespaces,
+// the namespace is ignored. The factory is created once and shared by all callers,
+// so that informers of the same resource and namespace are only run once.
+func (s *Scope) Factory(resource, namespace string) informers.SharedInformerFactory {
+	if slices.Contains(clusterScoped, resource) && resource != "namespaces" {
+		namespace = kmetav1.NamespaceAll
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := resource + "/" + namespace
+	if factory, ok := s.factories[key]; ok {
+		return factory
+	}
+
+	options := []informers.SharedInformerOption{}
+	if !slices.Contains(clusterScoped, resource) {
+		options = append(options, informers.WithNamespace(namespace))
+	}
+
+	if tweak := s.tweakListOptions(resource, namespace); tweak != nil {
+		options = append(options, informers.WithTweakListOptions(tweak))
+	}
+
+	factory := informers.NewSharedInformerFactoryWithOptions(s.clientset, 0, options...)
+	s.factories[key] = factory
+
+	return factory
+}
+
+// DynamicFactory returns the dynamic informer factory for the given custom resource in the given namespace,
+// which must be one of Namespaces. For cluster-scoped resources, the namespace is ignored.
+// Selectors are looked up by the group-qualified resource name, e.g. certificates.cert-manager.io.
+// As with Factory, the factory is created once and shared by all callers.
+func (s *Scope) DynamicFactory(
+	gvr kschema.GroupVersionResource, namespaced bool, namespace string,
+) dynamicinformer.DynamicSharedInformerFactory {
+	resource := gvr.GroupResource().String()
+	if !namespaced {
+		namespace = kmetav1.NamespaceAll
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := gvr.String() + "/" + namespace
+	if factory, ok := s.dynamicFactories[key]; ok {
+		return factory
+	}
+
+	var tweak dynamicinformer.TweakListOptionsFunc
+	if namespaced {
+		tweak = s.tweakListOptions(resource, namespace)
+	} else {
+		tweak = s.selectorsOnly(resource)
+	}
+
+	factory := dynamicinformer.NewFilteredDynamicSharedInformerFactory(s.dynamicClient, 0, namespace, tweak)
+	s.dynamicFactories[key] = factory
+
+	return factory
+}
+
+// Start starts all informers that have been requested from the factories so far.
+// Informers requested afterwards are not started.
+func (s *Scope) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, factory := range s.factories {
+		factory.Start(ctx.Done())
+	}
+
+	for _, factory := range s.dynamicFactories {
+		factory.Start(ctx.Done())
+	}
+}
+
+// WaitForCacheSync waits until the caches of all informers started by Start have been synced.
+func (s *Scope) WaitForCacheSync(ctx context.Context) error {
+	s.mu.Lock()
+	factories := maps.Clone(s.factories)
+	dynamicFactories := maps.Clone(s.dynamicFactories)
+	s.mu.Unlock()
+
+	for key, factory := range factories {
+		for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				return errors.Errorf("timed out waiting for caches of %s to sync", key)
+			}
+		}
+	}
+
+	for key, factory := range dynamicFactories {
+		for _, synced := range factory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				return errors.Errorf("timed out waiting for caches of %s to sync", key)
+			}
+		}
+	}
+
+	return nil
+}
+
+// WarmupFilter returns an SQL condition with positional placeholders and its arguments
+// that restricts the rows of the given resource to the given namespace, which must be one of Namespaces.
+// Returns an empty condition if the rows are not restricted.
+// Note that the selectors are not taken into account, so rows of resources that do not match them are
+// considered deleted.
+func (s *Scope) WarmupFilter(resource, namespace string) (string, []interface{}) {
+	var column string
+	switch resource {
+	case "namespaces":
+		column = "name"
+	case "nodes", "persistentvolumes":
+		return "", nil
+	default:
+		column = "namespace"
+	}
+
+	var condition string
+	var args []interface{}
+	switch {
+	case namespace != kmetav1.NamespaceAll:
+		condition, args = column+" = ?", []interface{}{namespace}
+	case len(s.config.ExcludeNamespaces) > 0:
+		// sqlx.In() only fails for arguments other than slices, which is not the case here.
+		condition, args, _ = sqlx.In(column+" NOT IN (?)", s.config.ExcludeNamespaces)
+	}
+
+	return condition, args
+}
+
+// tweakListOptions returns a function that applies the selectors of the given resource and
+// excludes namespaces out of scope, or nil if there is nothing to apply.
+// Resources are considered namespaced unless they are listed in clusterScoped.
+func (s *Scope) tweakListOptions(resource, namespace string) func(*kmetav1.ListOptions) {
+	selectors := s.config.Selectors[resource]
+	var fieldSelectors []string
+	if selectors.Field != "" {
+		fieldSelectors = append(fieldSelectors, selectors.Field)
+	}
+
+	switch resource {
+	case "namespaces":
+		if namespace != kmetav1.NamespaceAll {
+			fieldSelectors = append(fieldSelectors, "metadata.name="+namespace)
+		}
+
+		for _, exclude := range s.config.ExcludeNamespaces {
+			fieldSelectors = append(fieldSelectors, "metadata.name!="+exclude)
+		}
+	case "nodes", "persistentvolumes":
+	default:
+		for _, exclude := range s.config.ExcludeNamespaces {
+			fieldSelectors = append(fieldSelectors, "metadata.namespace!="+exclude)
+		}
+	}
+
+	if selectors.Label == "" && len(fieldSelectors) == 0 {
+		return nil
+	}
+
+	return func(o *kmetav1.ListOptions) {
+		o.LabelSelector = selectors.Label
+		o.FieldSelector = strings.Join(fieldSelectors, ",")
+	}
+}
+
+// selectorsOnly returns a function that applies the selectors of the given cluster-scoped resource,
+// or nil if there are none.
+func (s *Scope) selectorsOnly(resource string) func(*kmetav1.ListOptions) {
+	selectors, ok := s.config.Selectors[resource]
+	if !ok || selectors.Label == "" && selectors.Field == "" {
+		return nil
+	}
+
+	return func(o *kmetav1.ListOptions) {
+		o.LabelSelector = selectors.Label
+		o.FieldSelector = selectors.Field
+	}
+}
@@ -0,0 +1,23 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config defines synchronization configuration.
+type Config struct {
+	// ReconcileInterval is the interval of the reconciliation between the informer caches and the database.
+	// Reconciliation is disabled if zero.
+	ReconcileInterval time.Duration `yaml:"reconcile_interval" env:"RECONCILE_INTERVAL" default:"1h"`
+}
+
+// Validate checks constraints in the supplied synchronization configuration and returns an error if they are violated.
+func (c *Config) Validate() error {
+	if c.ReconcileInterval < 0 {
+		return errors.New("'reconcile_interval' must not be negative")
+	}
+
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-logr/logr"
+	"github.com/icinga/icinga-kubernetes/pkg/telemetry"
 	"github.com/pkg/errors"
 	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -15,18 +16,26 @@ type Controller struct {
 	informer cache.SharedIndexInformer
 	log      logr.Logger
 	queue    workqueue.TypedRateLimitingInterface[EventHandlerItem]
+	synced   func()
 }
 
+// NewController creates a new Controller for the given informer.
+// name identifies the workqueue of the controller in the telemetry metrics and must be unique.
 func NewController(
 	informer cache.SharedIndexInformer,
 	log logr.Logger,
+	name string,
 ) *Controller {
-
 	return &Controller{
 		informer: informer,
 		log:      log,
-		queue: workqueue.NewTypedRateLimitingQueue[EventHandlerItem](
+		synced:   telemetry.Health.Pending(name + " cache sync"),
+		queue: workqueue.NewTypedRateLimitingQueueWithConfig[EventHandlerItem](
 			workqueue.DefaultTypedControllerRateLimiter[EventHandlerItem](),
+			workqueue.TypedRateLimitingQueueConfig[EventHandlerItem]{
+				Name:            name,
+				MetricsProvider: telemetry.WorkqueueMetricsProvider{},
+			},
 		),
 	}
 }
@@ -35,6 +44,11 @@ func (c *Controller) Announce(obj interface{}) error {
 	return c.informer.GetStore().Add(obj)
 }
 
+// Enqueue adds the given item to the queue as if it was reported by the informer.
+func (c *Controller) Enqueue(item EventHandlerItem) {
+	c.queue.Add(item)
+}
+
 func (c *Controller) Stream(ctx context.Context, sink *Sink) error {
 	_, err := c.informer.AddEventHandler(NewEventHandler(c.queue, c.log.WithName("events")))
 	if err != nil {
@@ -48,12 +62,17 @@ func (c *Controller) Stream(ctx context.Context, sink *Sink) error {
 		c.queue.ShutDown()
 	}()
 
-	go c.informer.Run(ctx.Done())
+	// The informer may already have been started to warm up its cache, e.g. on standby instances.
+	if started, ok := c.informer.(interface{ HasStarted() bool }); !ok || !started.HasStarted() {
+		go c.informer.Run(ctx.Done())
+	}
 
 	if !cache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced) {
 		return errors.New("timed out waiting for caches to sync")
 	}
 
+	c.synced()
+
 	return c.stream(ctx, sink)
 }
 
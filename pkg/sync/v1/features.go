@@ -1,14 +1,23 @@
 package v1
 
-import "github.com/icinga/icinga-go-library/database"
+import (
+	"time"
+
+	"github.com/icinga/icinga-go-library/database"
+)
 
 type Feature func(*Features)
 
 type Features struct {
-	noDelete bool
-	noWarmup bool
-	onDelete database.OnSuccess[any]
-	onUpsert database.OnSuccess[any]
+	name             string
+	noDelete         bool
+	noWarmup         bool
+	onDelete         database.OnSuccess[any]
+	onUpsert         database.OnSuccess[any]
+	reconcile        time.Duration
+	requeuer         *Requeuer
+	warmupFilter     string
+	warmupFilterArgs []interface{}
 }
 
 func NewFeatures(features ...Feature) *Features {
@@ -20,6 +29,10 @@ func NewFeatures(features ...Feature) *Features {
 	return f
 }
 
+func (f *Features) Name() string {
+	return f.name
+}
+
 func (f *Features) NoDelete() bool {
 	return f.noDelete
 }
@@ -36,6 +49,27 @@ func (f *Features) OnUpsert() database.OnSuccess[any] {
 	return f.onUpsert
 }
 
+func (f *Features) Reconcile() time.Duration {
+	return f.reconcile
+}
+
+func (f *Features) Requeuer() *Requeuer {
+	return f.requeuer
+}
+
+func (f *Features) WarmupFilter() (string, []interface{}) {
+	return f.warmupFilter, f.warmupFilterArgs
+}
+
+// WithName sets the name of the sync, which distinguishes its workqueue and cache sync in the telemetry,
+// e.g. if multiple syncs of the same resource are running for different namespaces.
+// Defaults to the table of the resource.
+func WithName(name string) Feature {
+	return func(f *Features) {
+		f.name = name
+	}
+}
+
 func WithNoDelete() Feature {
 	return func(f *Features) {
 		f.noDelete = true
@@ -59,3 +93,39 @@ func WithOnUpsert(fn database.OnSuccess[any]) Feature {
 		f.onUpsert = fn
 	}
 }
+
+// WithReconcile periodically reconciles the informer cache with the database in the given interval.
+// Rows of resources no longer in the cache are deleted, unless WithNoDelete is also used,
+// and resources missing in the database are upserted again.
+func WithReconcile(interval time.Duration) Feature {
+	return func(f *Features) {
+		f.reconcile = interval
+	}
+}
+
+// WithRequeuer registers the sync with the given Requeuer,
+// so that its resources can be upserted again without a change reported by the informer.
+func WithRequeuer(r *Requeuer) Feature {
+	return func(f *Features) {
+		f.requeuer = r
+	}
+}
+
+// WithWarmupFilter restricts the rows loaded during warmup to those matching the given SQL condition,
+// which uses positional placeholders for args. Rows that are not loaded are never deleted by the sync.
+// If used multiple times, all conditions must match. Empty conditions are ignored.
+func WithWarmupFilter(condition string, args []interface{}) Feature {
+	return func(f *Features) {
+		if condition == "" {
+			return
+		}
+
+		if f.warmupFilter != "" {
+			f.warmupFilter += " AND " + condition
+		} else {
+			f.warmupFilter = condition
+		}
+
+		f.warmupFilterArgs = append(f.warmupFilterArgs, args...)
+	}
+}
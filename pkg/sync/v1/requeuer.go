@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"sync"
+
+	schemav1 "github.com/icinga/icinga-kubernetes/pkg/schema/v1"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Requeuer upserts resources of the syncs it is registered with again on demand,
+// e.g. if their state depends on data that does not come from the Kubernetes API.
+type Requeuer struct {
+	mu      sync.RWMutex
+	targets []requeueTarget
+}
+
+// requeueTarget is a sync the Requeuer is registered with.
+type requeueTarget struct {
+	informer   cache.SharedIndexInformer
+	controller *Controller
+}
+
+// NewRequeuer creates a new Requeuer, see WithRequeuer.
+func NewRequeuer() *Requeuer {
+	return &Requeuer{}
+}
+
+// Requeue enqueues an update of the resource with the given namespace/name key
+// in the sync whose informer knows the resource. Unknown resources are ignored.
+func (r *Requeuer) Requeue(key string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, target := range r.targets {
+		item, exists, err := target.informer.GetStore().GetByKey(key)
+		if err != nil || !exists {
+			continue
+		}
+
+		target.controller.Enqueue(EventHandlerItem{
+			Type: EventUpdate,
+			Id:   schemav1.EnsureUUID(item.(kmetav1.Object).GetUID()),
+			KKey: key,
+		})
+
+		return
+	}
+}
+
+func (r *Requeuer) register(informer cache.SharedIndexInformer, c *Controller) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.targets = append(r.targets, requeueTarget{informer: informer, controller: c})
+}
@@ -2,12 +2,19 @@ package v1
 
 import (
 	"context"
+	"fmt"
 	"github.com/go-logr/logr"
 	"github.com/icinga/icinga-go-library/com"
+	"github.com/icinga/icinga-go-library/periodic"
+	"github.com/icinga/icinga-go-library/types"
 	"github.com/icinga/icinga-kubernetes/pkg/cluster"
 	"github.com/icinga/icinga-kubernetes/pkg/database"
 	schemav1 "github.com/icinga/icinga-kubernetes/pkg/schema/v1"
+	"github.com/icinga/icinga-kubernetes/pkg/telemetry"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
+	kmetav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/tools/cache"
 )
@@ -34,12 +41,17 @@ func NewSync(
 }
 
 func (s *Sync) Run(ctx context.Context, features ...Feature) error {
-	controller := NewController(s.informer, s.log.WithName("controller"))
-
 	with := NewFeatures(features...)
 
+	name := with.Name()
+	if name == "" {
+		name = database.TableName(s.factory())
+	}
+
+	controller := NewController(s.informer, s.log.WithName("controller"), name)
+
 	if !with.NoWarmup() {
-		if err := s.warmup(ctx, controller); err != nil {
+		if err := s.warmup(ctx, controller, with); err != nil {
 			return err
 		}
 	}
@@ -47,15 +59,26 @@ func (s *Sync) Run(ctx context.Context, features ...Feature) error {
 	return s.sync(ctx, controller, features...)
 }
 
-func (s *Sync) warmup(ctx context.Context, c *Controller) error {
+func (s *Sync) warmup(ctx context.Context, c *Controller, with *Features) error {
 	g, ctx := errgroup.WithContext(ctx)
 
 	meta := &schemav1.Meta{ClusterUuid: cluster.ClusterUuidFromContext(ctx)}
 	query := s.db.BuildSelectStmt(s.factory(), meta) + ` WHERE cluster_uuid=:cluster_uuid`
+	args := []interface{}{meta}
+
+	if filter, filterArgs := with.WarmupFilter(); filter != "" {
+		q, namedArgs, err := sqlx.Named(query, meta)
+		if err != nil {
+			return errors.Wrapf(err, "cannot bind named parameters of %q", query)
+		}
+
+		query = q + " AND " + filter
+		args = append(namedArgs, filterArgs...)
+	}
 
 	entities, errs := s.db.YieldAll(ctx, func() (interface{}, error) {
 		return s.factory(), nil
-	}, query, meta)
+	}, query, args...)
 
 	// Let errors from YieldAll() cancel the group.
 	com.ErrgroupReceive(g, errs)
@@ -94,6 +117,10 @@ func (s *Sync) sync(ctx context.Context, c *Controller, features ...Feature) err
 
 	with := NewFeatures(features...)
 
+	if r := with.Requeuer(); r != nil {
+		r.register(s.informer, c)
+	}
+
 	g, ctx := errgroup.WithContext(ctx)
 	g.Go(func() error {
 		defer runtime.HandleCrash()
@@ -128,6 +155,13 @@ func (s *Sync) sync(ctx context.Context, c *Controller, features ...Feature) err
 				database.WithBlocking(), database.WithCascading(), database.WithOnSuccess(with.OnDelete()))
 		}
 	})
+	if with.Reconcile() > 0 {
+		g.Go(func() error {
+			defer runtime.HandleCrash()
+
+			return s.reconcile(ctx, c, with)
+		})
+	}
 	g.Go(func() error {
 		defer runtime.HandleCrash()
 
@@ -138,6 +172,8 @@ func (s *Sync) sync(ctx context.Context, c *Controller, features ...Feature) err
 					return nil
 				}
 
+				telemetry.SyncErrors.WithLabelValues(database.TableName(s.factory())).Inc()
+
 				s.log.Error(err, "sync error")
 			case <-ctx.Done():
 				return ctx.Err()
@@ -148,3 +184,82 @@ func (s *Sync) sync(ctx context.Context, c *Controller, features ...Feature) err
 
 	return g.Wait()
 }
+
+// reconcile periodically compares the resources in the informer cache with the rows in the database
+// that are in scope of the warmup filter. Deletes are enqueued for rows of resources no longer in the cache,
+// unless deletes are disabled, and upserts are enqueued for resources missing in the database.
+func (s *Sync) reconcile(ctx context.Context, c *Controller, with *Features) error {
+	if !cache.WaitForCacheSync(ctx.Done(), s.informer.HasSynced) {
+		return ctx.Err()
+	}
+
+	table := database.TableName(s.factory())
+
+	defer periodic.Start(ctx, with.Reconcile(), func(periodic.Tick) {
+		orphaned, missing, err := s.diff(ctx, c, with)
+		if err != nil {
+			s.log.Error(err, "cannot reconcile")
+
+			return
+		}
+
+		telemetry.ReconcileDrift.WithLabelValues(table, "orphaned").Add(float64(orphaned))
+		telemetry.ReconcileDrift.WithLabelValues(table, "missing").Add(float64(missing))
+
+		if orphaned+missing > 0 {
+			s.log.Info("Reconciled drift between cache and database", "orphaned", orphaned, "missing", missing)
+		}
+	}).Stop()
+
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+// diff enqueues deletes for orphaned rows and upserts for missing rows and returns their numbers.
+func (s *Sync) diff(ctx context.Context, c *Controller, with *Features) (orphaned, missing int, err error) {
+	// Rows are fetched before listing the cache, as resources are added to the cache before they are upserted.
+	// This way, a row is never considered orphaned just because its resource was added in the meantime.
+	query := fmt.Sprintf("SELECT uuid FROM %s WHERE cluster_uuid = ?", database.TableName(s.factory()))
+	args := []interface{}{cluster.ClusterUuidFromContext(ctx)}
+	if filter, filterArgs := with.WarmupFilter(); filter != "" {
+		query += " AND " + filter
+		args = append(args, filterArgs...)
+	}
+
+	var rows []types.UUID
+	if err := s.db.SelectContext(ctx, &rows, s.db.Rebind(query), args...); err != nil {
+		return 0, 0, database.CantPerformQuery(err, query)
+	}
+
+	cached := make(map[types.UUID]string)
+	for _, item := range s.informer.GetStore().List() {
+		key, err := cache.MetaNamespaceKeyFunc(item)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "cannot make key")
+		}
+
+		cached[schemav1.EnsureUUID(item.(kmetav1.Object).GetUID())] = key
+	}
+
+	for _, id := range rows {
+		if _, ok := cached[id]; ok {
+			delete(cached, id)
+
+			continue
+		}
+
+		if !with.NoDelete() {
+			// There is no key, so the sink is told to delete the row.
+			c.Enqueue(EventHandlerItem{Type: EventDelete, Id: id})
+			orphaned++
+		}
+	}
+
+	for id, key := range cached {
+		c.Enqueue(EventHandlerItem{Type: EventUpdate, Id: id, KKey: key})
+		missing++
+	}
+
+	return orphaned, missing, nil
+}
@@ -0,0 +1,31 @@
+package telemetry
+
+import (
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config defines telemetry configuration.
+type Config struct {
+	// If Listen is the empty string, the HTTP listener is disabled.
+	Listen string `yaml:"listen" env:"LISTEN"`
+	// KubernetesApiTimeout is the time after which the liveness check fails if the Kubernetes API is unreachable.
+	KubernetesApiTimeout time.Duration `yaml:"kubernetes_api_timeout" env:"KUBERNETES_API_TIMEOUT" default:"5m"`
+}
+
+// Validate checks constraints in the supplied telemetry configuration and returns an error if they are violated.
+func (c *Config) Validate() error {
+	if c.Listen != "" {
+		if _, _, err := net.SplitHostPort(c.Listen); err != nil {
+			return errors.Wrap(err, "'listen' invalid")
+		}
+	}
+
+	if c.KubernetesApiTimeout <= 0 {
+		return errors.New("'kubernetes_api_timeout' must be positive")
+	}
+
+	return nil
+}
@@ -0,0 +1,103 @@
+package telemetry
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// heartbeatTimeout is the time after which the heartbeat is considered stalled.
+// It must be longer than the interval of the heartbeat, which is 55 seconds.
+const heartbeatTimeout = 3 * time.Minute
+
+// Health tracks the readiness and liveness of the current process.
+var Health = &health{pending: make(map[string]int)}
+
+type health struct {
+	mu                  sync.Mutex
+	pending             map[string]int
+	heartbeat           time.Time
+	apiUnreachableSince time.Time
+}
+
+// Pending registers a condition with the given name that has to be met before the process is ready.
+// The returned function marks the condition as met and may be called multiple times.
+// Conditions with the same name are tracked separately, i.e. all of them have to be met.
+func (h *health) Pending(name string) (done func()) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.pending[name]++
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() {
+			h.mu.Lock()
+			defer h.mu.Unlock()
+
+			if h.pending[name]--; h.pending[name] <= 0 {
+				delete(h.pending, name)
+			}
+		})
+	}
+}
+
+// Heartbeat records a heartbeat at time t and whether the Kubernetes API was reachable at that time.
+func (h *health) Heartbeat(t time.Time, apiReachable bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.heartbeat = t
+
+	if apiReachable {
+		h.apiUnreachableSince = time.Time{}
+	} else if h.apiUnreachableSince.IsZero() {
+		h.apiUnreachableSince = t
+	}
+}
+
+// Ready returns an error naming the conditions that are not met yet, if any.
+func (h *health) Ready() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.pending) == 0 {
+		return nil
+	}
+
+	pending := make([]string, 0, len(h.pending))
+	for name := range h.pending {
+		pending = append(pending, name)
+	}
+	slices.Sort(pending)
+
+	return errors.Errorf("waiting for %s", strings.Join(pending, ", "))
+}
+
+// Live returns an error if the heartbeat has stalled or
+// if the Kubernetes API has been unreachable for longer than apiTimeout.
+// Before the first heartbeat, the process is always considered live.
+func (h *health) Live(apiTimeout time.Duration) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.heartbeat.IsZero() {
+		return nil
+	}
+
+	if since := time.Since(h.heartbeat); since > heartbeatTimeout {
+		return errors.Errorf("heartbeat stalled for %s", since.Round(time.Second))
+	}
+
+	if !h.apiUnreachableSince.IsZero() {
+		if since := time.Since(h.apiUnreachableSince); since > apiTimeout {
+			return errors.Errorf("Kubernetes API unreachable for %s", since.Round(time.Second))
+		}
+	}
+
+	return nil
+}
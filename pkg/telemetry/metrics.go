@@ -0,0 +1,88 @@
+package telemetry
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const namespace = "icinga_kubernetes"
+
+// Registry contains all metrics about Icinga for Kubernetes itself.
+var Registry = prometheus.NewRegistry()
+
+var (
+	// DatabaseRows counts the rows upserted or deleted per table and operation.
+	DatabaseRows = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "rows_total",
+		Help:      "Number of rows upserted or deleted.",
+	}, []string{"table", "operation"})
+
+	// DatabaseQueryDuration observes the duration of bulk upserts and deletes per table and operation.
+	DatabaseQueryDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "database",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of bulk upserts and deletes.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"table", "operation"})
+
+	// SyncErrors counts the errors reported by the sink of a sync per resource kind.
+	SyncErrors = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "errors_total",
+		Help:      "Number of errors while synchronizing resources.",
+	}, []string{"kind"})
+
+	// ReconcileDrift counts the rows found to be orphaned or missing by the reconciliation per resource kind.
+	ReconcileDrift = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "sync",
+		Name:      "reconcile_drift_total",
+		Help:      "Number of orphaned or missing rows found by the reconciliation.",
+	}, []string{"kind", "drift"})
+
+	// NotificationEvents counts the events submitted to Icinga Notifications per resource kind.
+	NotificationEvents = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notifications",
+		Name:      "events_total",
+		Help:      "Number of events submitted to Icinga Notifications.",
+	}, []string{"kind"})
+
+	// NotificationEventsFailed counts the events that could not be submitted to Icinga Notifications
+	// per resource kind.
+	NotificationEventsFailed = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "notifications",
+		Name:      "events_failed_total",
+		Help:      "Number of events that could not be submitted to Icinga Notifications.",
+	}, []string{"kind"})
+
+	// PrometheusQueryDuration observes the duration of Prometheus queries per metric category.
+	PrometheusQueryDuration = promauto.With(Registry).NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "prometheus",
+		Name:      "query_duration_seconds",
+		Help:      "Duration of Prometheus queries.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"category"})
+
+	// CleanupRowsDeleted counts the rows deleted by the periodic cleanup per table.
+	CleanupRowsDeleted = promauto.With(Registry).NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "cleanup",
+		Name:      "rows_deleted_total",
+		Help:      "Number of rows deleted by the periodic cleanup.",
+	}, []string{"table"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
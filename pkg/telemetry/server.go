@@ -0,0 +1,62 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Serve serves the metrics of Registry at /metrics and the liveness and readiness of the process
+// at /healthz and /readyz on the configured address until ctx is canceled.
+func Serve(ctx context.Context, c *Config, log logr.Logger) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(Registry, promhttp.HandlerOpts{}))
+	mux.Handle("/healthz", healthHandler(func() error { return Health.Live(c.KubernetesApiTimeout) }))
+	mux.Handle("/readyz", healthHandler(Health.Ready))
+
+	server := &http.Server{
+		Addr:              c.Listen,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errs := make(chan error, 1)
+	go func() {
+		log.Info("Serving telemetry", "address", c.Listen)
+
+		errs <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errs:
+		return errors.Wrap(err, "cannot serve telemetry")
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		_ = server.Shutdown(shutdownCtx)
+
+		return ctx.Err()
+	}
+}
+
+// healthHandler responds with 200 OK if check succeeds and with 503 Service Unavailable and the error otherwise.
+func healthHandler(check func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+
+		if err := check(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = fmt.Fprintln(w, err)
+
+			return
+		}
+
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+}
@@ -1,9 +1,17 @@
 package mysql
 
-import _ "embed"
+import (
+	"embed"
+)
 
 // Schema is a copy of schema.sql. It resides here
 // and not in ../../cmd/icinga-kubernetes/main.go due to go:embed restrictions.
 //
 //go:embed schema.sql
 var Schema string
+
+// Upgrades contains the schema upgrade scripts of the upgrades directory.
+// Each script is named after the schema version it upgrades to, e.g. upgrades/0.5.0.sql.
+//
+//go:embed all:upgrades
+var Upgrades embed.FS
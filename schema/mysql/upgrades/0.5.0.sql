@@ -0,0 +1,289 @@
+ALTER TABLE kubernetes_instance
+  ADD COLUMN role enum('leader', 'standby') COLLATE utf8mb4_unicode_ci NOT NULL DEFAULT 'leader' AFTER kubernetes_api_reachable;
+
+ALTER TABLE persistent_volume
+  ADD COLUMN icinga_state enum('unknown', 'pending', 'ok', 'warning', 'critical') COLLATE utf8mb4_unicode_ci NOT NULL AFTER message,
+  ADD COLUMN icinga_state_reason text NOT NULL AFTER icinga_state;
+
+ALTER TABLE pvc
+  ADD COLUMN icinga_state enum('unknown', 'pending', 'ok', 'warning', 'critical') COLLATE utf8mb4_unicode_ci NOT NULL AFTER storage_class,
+  ADD COLUMN icinga_state_reason text NOT NULL AFTER icinga_state;
+
+ALTER TABLE container_log
+  ADD INDEX idx_container_log_last_update (last_update) COMMENT 'Filter for deleting old container logs';
+
+CREATE TABLE custom_resource (
+  uuid binary(16) NOT NULL,
+  cluster_uuid binary(16) NOT NULL,
+  api_group varchar(253) COLLATE utf8mb4_unicode_ci NOT NULL,
+  api_version varchar(63) COLLATE utf8mb4_unicode_ci NOT NULL,
+  resource varchar(63) COLLATE utf8mb4_unicode_ci NOT NULL,
+  kind varchar(63) COLLATE utf8mb4_unicode_ci NOT NULL,
+  namespace varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  name varchar(253) COLLATE utf8mb4_unicode_ci NOT NULL,
+  uid varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  yaml mediumblob DEFAULT NULL,
+  icinga_state enum('unknown', 'pending', 'ok', 'warning', 'critical') COLLATE utf8mb4_unicode_ci NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint unsigned NOT NULL,
+  PRIMARY KEY (uuid),
+  INDEX idx_custom_resource_api_group_resource (api_group, resource) COMMENT 'Filter for synchronizing resources of one API'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE custom_resource_annotation (
+  custom_resource_uuid binary(16) NOT NULL,
+  annotation_uuid binary(16) NOT NULL,
+  PRIMARY KEY (custom_resource_uuid, annotation_uuid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE custom_resource_condition (
+  custom_resource_uuid binary(16) NOT NULL,
+  type varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  status varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  last_transition bigint unsigned NOT NULL,
+  reason varchar(255) COLLATE utf8mb4_unicode_ci NOT NULL,
+  message text,
+  PRIMARY KEY (custom_resource_uuid, type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE custom_resource_label (
+  custom_resource_uuid binary(16) NOT NULL,
+  label_uuid binary(16) NOT NULL,
+  PRIMARY KEY (custom_resource_uuid, label_uuid)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE downtime (
+  uuid binary(16) NOT NULL,
+  cluster_uuid binary(16) NOT NULL,
+  namespace varchar(255) COLLATE utf8mb4_unicode_ci NULL DEFAULT NULL,
+  label_selector text NULL DEFAULT NULL,
+  resource_uuid binary(16) NULL DEFAULT NULL,
+  start_time bigint unsigned NOT NULL,
+  end_time bigint unsigned NOT NULL,
+  comment text NOT NULL,
+  locked enum('n', 'y') COLLATE utf8mb4_unicode_ci NOT NULL,
+  PRIMARY KEY (uuid),
+  INDEX idx_downtime_cluster_uuid_end_time (cluster_uuid, end_time) COMMENT 'Filter for loading the downtimes of a cluster that have not ended yet'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE notification_outbox (
+  uuid binary(16) NOT NULL,
+  cluster_uuid binary(16) NOT NULL,
+  kind varchar(63) COLLATE utf8mb4_unicode_ci NOT NULL,
+  name varchar(510) COLLATE utf8mb4_unicode_ci NOT NULL,
+  severity varchar(63) COLLATE utf8mb4_unicode_ci NOT NULL,
+  message text NOT NULL,
+  url text NOT NULL,
+  tags text NOT NULL,
+  extra_tags text NOT NULL,
+  labels text NOT NULL,
+  annotations text NOT NULL,
+  sinks text NOT NULL,
+  deleted enum('n', 'y') COLLATE utf8mb4_unicode_ci NOT NULL,
+  version bigint unsigned NOT NULL,
+  created bigint unsigned NOT NULL,
+  PRIMARY KEY (uuid),
+  INDEX idx_notification_outbox_cluster_uuid_created (cluster_uuid, created) COMMENT 'Draining the events of a cluster in order'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE notification_state (
+  uuid binary(16) NOT NULL,
+  cluster_uuid binary(16) NOT NULL,
+  kind varchar(63) COLLATE utf8mb4_unicode_ci NOT NULL,
+  severity varchar(63) COLLATE utf8mb4_unicode_ci NOT NULL,
+  message text NOT NULL,
+  sent bigint unsigned NOT NULL,
+  PRIMARY KEY (uuid),
+  INDEX idx_notification_state_cluster_uuid (cluster_uuid) COMMENT 'Filter for restoring the states of a cluster'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE pvc_metric (
+  pvc_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  value double NOT NULL,
+  PRIMARY KEY (pvc_uuid, timestamp, category, name),
+  INDEX idx_pvc_metric_timestamp (timestamp) COMMENT 'Filter for deleting old PVC metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE prometheus_cluster_metric_1h (
+  cluster_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (cluster_uuid, timestamp, category, name),
+  INDEX idx_prometheus_cluster_metric_1h_timestamp (timestamp) COMMENT 'Filter for deleting old hourly cluster metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE prometheus_cluster_metric_5m (
+  cluster_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (cluster_uuid, timestamp, category, name),
+  INDEX idx_prometheus_cluster_metric_5m_timestamp (timestamp) COMMENT 'Filter for deleting old 5-minute cluster metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE prometheus_container_metric_1h (
+  container_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (container_uuid, timestamp, category, name),
+  INDEX idx_prometheus_container_metric_1h_timestamp (timestamp) COMMENT 'Filter for deleting old hourly container metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE prometheus_container_metric_5m (
+  container_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (container_uuid, timestamp, category, name),
+  INDEX idx_prometheus_container_metric_5m_timestamp (timestamp) COMMENT 'Filter for deleting old 5-minute container metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE prometheus_node_metric_1h (
+  node_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (node_uuid, timestamp, category, name),
+  INDEX idx_prometheus_node_metric_1h_timestamp (timestamp) COMMENT 'Filter for deleting old hourly node metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE prometheus_node_metric_5m (
+  node_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (node_uuid, timestamp, category, name),
+  INDEX idx_prometheus_node_metric_5m_timestamp (timestamp) COMMENT 'Filter for deleting old 5-minute node metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE prometheus_pod_metric_1h (
+  pod_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (pod_uuid, timestamp, category, name),
+  INDEX idx_prometheus_pod_metric_1h_timestamp (timestamp) COMMENT 'Filter for deleting old hourly pod metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE prometheus_pod_metric_5m (
+  pod_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (pod_uuid, timestamp, category, name),
+  INDEX idx_prometheus_pod_metric_5m_timestamp (timestamp) COMMENT 'Filter for deleting old 5-minute pod metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE pvc_metric_1h (
+  pvc_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (pvc_uuid, timestamp, category, name),
+  INDEX idx_pvc_metric_1h_timestamp (timestamp) COMMENT 'Filter for deleting old hourly PVC metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE TABLE pvc_metric_5m (
+  pvc_uuid binary(16) NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double NOT NULL,
+  avg double NOT NULL,
+  max double NOT NULL,
+  PRIMARY KEY (pvc_uuid, timestamp, category, name),
+  INDEX idx_pvc_metric_5m_timestamp (timestamp) COMMENT 'Filter for deleting old 5-minute PVC metrics'
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_bin;
+
+CREATE VIEW prometheus_cluster_metric_series AS
+  SELECT cluster_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_cluster_metric
+  UNION ALL
+  SELECT cluster_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_cluster_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_cluster_metric)
+  UNION ALL
+  SELECT cluster_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_cluster_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_cluster_metric_5m);
+
+CREATE VIEW prometheus_container_metric_series AS
+  SELECT container_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_container_metric
+  UNION ALL
+  SELECT container_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_container_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_container_metric)
+  UNION ALL
+  SELECT container_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_container_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_container_metric_5m);
+
+CREATE VIEW prometheus_node_metric_series AS
+  SELECT node_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_node_metric
+  UNION ALL
+  SELECT node_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_node_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_node_metric)
+  UNION ALL
+  SELECT node_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_node_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_node_metric_5m);
+
+CREATE VIEW prometheus_pod_metric_series AS
+  SELECT pod_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_pod_metric
+  UNION ALL
+  SELECT pod_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_pod_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_pod_metric)
+  UNION ALL
+  SELECT pod_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_pod_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_pod_metric_5m);
+
+CREATE VIEW pvc_metric_series AS
+  SELECT pvc_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM pvc_metric
+  UNION ALL
+  SELECT pvc_uuid, timestamp, category, name, min, avg, max
+  FROM pvc_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM pvc_metric)
+  UNION ALL
+  SELECT pvc_uuid, timestamp, category, name, min, avg, max
+  FROM pvc_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM pvc_metric_5m);
@@ -0,0 +1,1535 @@
+CREATE TYPE boolenum AS ENUM ('n', 'y');
+CREATE TYPE image_pull_policy AS ENUM ('Always', 'Never', 'IfNotPresent');
+CREATE TYPE container_state AS ENUM ('Waiting', 'Running', 'Terminated');
+CREATE TYPE icinga_state AS ENUM ('unknown', 'pending', 'ok', 'warning', 'critical');
+CREATE TYPE concurrency_policy AS ENUM ('Allow', 'Forbid', 'Replace');
+CREATE TYPE update_strategy AS ENUM ('RollingUpdate', 'OnDelete');
+CREATE TYPE condition_status AS ENUM ('true', 'false', 'unknown');
+CREATE TYPE deployment_strategy AS ENUM ('Recreate', 'RollingUpdate');
+CREATE TYPE port_protocol AS ENUM ('TCP', 'UDP', 'SCTP');
+CREATE TYPE address_type AS ENUM ('IPv4', 'IPv6', 'FQDN');
+CREATE TYPE endpoint_target_ref_kind AS ENUM ('pod', 'node');
+CREATE TYPE path_type AS ENUM ('Exact', 'Prefix', 'ImplementationSpecific');
+CREATE TYPE completion_mode AS ENUM ('NonIndexed', 'Indexed');
+CREATE TYPE namespace_phase AS ENUM ('Active', 'Terminating');
+CREATE TYPE persistent_volume_phase AS ENUM ('Pending', 'Available', 'Bound', 'Released', 'Failed');
+CREATE TYPE volume_mode AS ENUM ('Filesystem', 'Block');
+CREATE TYPE reclaim_policy AS ENUM ('Recycle', 'Delete', 'Retain');
+CREATE TYPE restart_policy AS ENUM ('Always', 'OnFailure', 'Never');
+CREATE TYPE pod_phase AS ENUM ('Pending', 'Running', 'Succeeded', 'Failed');
+CREATE TYPE pod_qos AS ENUM ('Guaranteed', 'Burstable', 'BestEffort');
+CREATE TYPE pvc_phase AS ENUM ('Pending', 'Bound', 'Lost');
+CREATE TYPE service_type AS ENUM ('ClusterIP', 'NodePort', 'LoadBalancer', 'ExternalName');
+CREATE TYPE session_affinity AS ENUM ('None', 'ClientIP');
+CREATE TYPE traffic_policy AS ENUM ('Cluster', 'Local');
+CREATE TYPE ip_families AS ENUM ('IPv4', 'IPv6', 'DualStack', 'Unknown');
+CREATE TYPE ip_family_policy AS ENUM ('SingleStack', 'PreferDualStack', 'RequireDualStack');
+CREATE TYPE pod_management_policy AS ENUM ('OrderedReady', 'Parallel');
+CREATE TYPE pvc_retention_policy AS ENUM ('Retain', 'Delete');
+CREATE TYPE instance_role AS ENUM ('leader', 'standby');
+
+CREATE TABLE cluster (
+  uuid bytea NOT NULL,
+  name varchar(255) DEFAULT NULL,
+
+  CONSTRAINT pk_cluster PRIMARY KEY (uuid)
+);
+
+CREATE TABLE annotation (
+  uuid bytea NOT NULL,
+  name varchar(317) NOT NULL,
+  value text NOT NULL,
+
+  CONSTRAINT pk_annotation PRIMARY KEY (uuid)
+);
+
+CREATE TABLE resource_annotation (
+  resource_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_resource_annotation PRIMARY KEY (resource_uuid, annotation_uuid)
+);
+
+CREATE TABLE label (
+  uuid bytea NOT NULL,
+  name varchar(317) NOT NULL,
+  value varchar(255) NOT NULL,
+
+  CONSTRAINT pk_label PRIMARY KEY (uuid)
+);
+
+CREATE TABLE resource_label (
+  resource_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_resource_label PRIMARY KEY (resource_uuid, label_uuid)
+);
+
+CREATE TABLE config_map (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  immutable boolenum NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_config_map PRIMARY KEY (uuid)
+);
+
+CREATE TABLE config_map_annotation (
+  config_map_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_config_map_annotation PRIMARY KEY (config_map_uuid, annotation_uuid)
+);
+
+CREATE TABLE config_map_label (
+  config_map_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_config_map_label PRIMARY KEY (config_map_uuid, label_uuid)
+);
+
+CREATE TABLE container (
+  uuid bytea NOT NULL,
+  pod_uuid bytea NOT NULL,
+  name varchar(255) NOT NULL,
+  image varchar(512) NOT NULL,
+  image_pull_policy image_pull_policy DEFAULT NULL,
+  cpu_limits bigint DEFAULT NULL,
+  cpu_requests bigint DEFAULT NULL,
+  memory_limits bigint DEFAULT NULL,
+  memory_requests bigint DEFAULT NULL,
+  state container_state DEFAULT NULL,
+  state_details text DEFAULT NULL,
+  ready boolenum NOT NULL,
+  started boolenum NOT NULL,
+  restart_count bigint NOT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text DEFAULT NULL,
+
+  CONSTRAINT pk_container PRIMARY KEY (uuid)
+);
+
+CREATE TABLE init_container (
+  uuid bytea NOT NULL,
+  pod_uuid bytea NOT NULL,
+  name varchar(255) NOT NULL,
+  image varchar(512) NOT NULL,
+  image_pull_policy image_pull_policy DEFAULT NULL,
+  cpu_limits bigint DEFAULT NULL,
+  cpu_requests bigint DEFAULT NULL,
+  memory_limits bigint DEFAULT NULL,
+  memory_requests bigint DEFAULT NULL,
+  state container_state DEFAULT NULL,
+  state_details text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text DEFAULT NULL,
+
+  CONSTRAINT pk_init_container PRIMARY KEY (uuid)
+);
+
+CREATE TABLE sidecar_container (
+  uuid bytea NOT NULL,
+  pod_uuid bytea NOT NULL,
+  name varchar(255) NOT NULL,
+  image varchar(512) NOT NULL,
+  image_pull_policy image_pull_policy DEFAULT NULL,
+  cpu_limits bigint DEFAULT NULL,
+  cpu_requests bigint DEFAULT NULL,
+  memory_limits bigint DEFAULT NULL,
+  memory_requests bigint DEFAULT NULL,
+  state container_state DEFAULT NULL,
+  state_details text DEFAULT NULL,
+  ready boolenum NOT NULL,
+  started boolenum NOT NULL,
+  restart_count bigint NOT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text DEFAULT NULL,
+
+  CONSTRAINT pk_sidecar_container PRIMARY KEY (uuid)
+);
+
+CREATE TABLE container_device (
+  container_uuid bytea NOT NULL,
+  pod_uuid bytea NOT NULL,
+  name varchar(253) NOT NULL,
+  path varchar(255) NOT NULL,
+
+  CONSTRAINT pk_container_device PRIMARY KEY (container_uuid, name)
+);
+
+CREATE TABLE container_log (
+  container_uuid bytea NOT NULL,
+  pod_uuid bytea NOT NULL,
+  logs text NOT NULL,
+  last_update bigint NOT NULL,
+
+  CONSTRAINT pk_container_log PRIMARY KEY (container_uuid)
+);
+
+CREATE INDEX idx_container_log_last_update ON container_log (last_update);
+COMMENT ON INDEX idx_container_log_last_update IS 'Filter for deleting old container logs';
+
+CREATE TABLE container_mount (
+  container_uuid bytea NOT NULL,
+  pod_uuid bytea NOT NULL,
+  volume_name varchar(255) NOT NULL,
+  path varchar(255) NOT NULL,
+  sub_path varchar(255) DEFAULT NULL,
+  read_only boolenum NOT NULL,
+
+  CONSTRAINT pk_container_mount PRIMARY KEY (container_uuid, volume_name)
+);
+
+CREATE TABLE cron_job (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  schedule varchar(255) NOT NULL,
+  timezone varchar(255) DEFAULT NULL,
+  starting_deadline_seconds bigint DEFAULT NULL,
+  concurrency_policy concurrency_policy NOT NULL,
+  suspend boolenum NOT NULL,
+  successful_jobs_history_limit bigint NOT NULL,
+  failed_jobs_history_limit bigint NOT NULL,
+  active bigint NOT NULL,
+  last_schedule_time bigint DEFAULT NULL,
+  last_successful_time bigint DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  yaml text DEFAULT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_cron_job PRIMARY KEY (uuid)
+);
+
+CREATE TABLE cron_job_annotation (
+  cron_job_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_cron_job_annotation PRIMARY KEY (cron_job_uuid, annotation_uuid)
+);
+
+CREATE TABLE cron_job_label (
+  cron_job_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_cron_job_label PRIMARY KEY (cron_job_uuid, label_uuid)
+);
+
+CREATE TABLE custom_resource (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  api_group varchar(253) NOT NULL,
+  api_version varchar(63) NOT NULL,
+  resource varchar(63) NOT NULL,
+  kind varchar(63) NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  yaml text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_custom_resource PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_custom_resource_api_group_resource ON custom_resource (api_group, resource);
+COMMENT ON INDEX idx_custom_resource_api_group_resource IS 'Filter for synchronizing resources of one API';
+
+CREATE TABLE custom_resource_annotation (
+  custom_resource_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_custom_resource_annotation PRIMARY KEY (custom_resource_uuid, annotation_uuid)
+);
+
+CREATE TABLE custom_resource_condition (
+  custom_resource_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status varchar(255) NOT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_custom_resource_condition PRIMARY KEY (custom_resource_uuid, type)
+);
+
+CREATE TABLE custom_resource_label (
+  custom_resource_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_custom_resource_label PRIMARY KEY (custom_resource_uuid, label_uuid)
+);
+
+CREATE TABLE daemon_set (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  update_strategy update_strategy NOT NULL,
+  min_ready_seconds bigint NOT NULL,
+  desired_number_scheduled bigint NOT NULL,
+  current_number_scheduled bigint NOT NULL,
+  number_misscheduled bigint NOT NULL,
+  number_ready bigint NOT NULL,
+  update_number_scheduled bigint NOT NULL,
+  number_available bigint NOT NULL,
+  number_unavailable bigint NOT NULL,
+  yaml text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_daemon_set PRIMARY KEY (uuid)
+);
+
+CREATE TABLE daemon_set_annotation (
+  daemon_set_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_daemon_set_annotation PRIMARY KEY (daemon_set_uuid, annotation_uuid)
+);
+
+CREATE TABLE daemon_set_condition (
+  daemon_set_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_daemon_set_condition PRIMARY KEY (daemon_set_uuid, type)
+);
+
+CREATE TABLE daemon_set_label (
+  daemon_set_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_daemon_set_label PRIMARY KEY (daemon_set_uuid, label_uuid)
+);
+
+CREATE TABLE daemon_set_owner (
+  daemon_set_uuid bytea NOT NULL,
+  owner_uuid bytea NOT NULL,
+  kind varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  controller boolenum NOT NULL,
+  block_owner_deletion boolenum NOT NULL,
+
+  CONSTRAINT pk_daemon_set_owner PRIMARY KEY (daemon_set_uuid, owner_uuid)
+);
+
+CREATE TABLE deployment (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255)  NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  strategy deployment_strategy NOT NULL,
+  min_ready_seconds bigint NOT NULL,
+  progress_deadline_seconds bigint NOT NULL,
+  paused boolenum NOT NULL,
+  desired_replicas bigint NOT NULL,
+  actual_replicas bigint NOT NULL,
+  updated_replicas bigint NOT NULL,
+  ready_replicas bigint NOT NULL,
+  available_replicas bigint NOT NULL,
+  unavailable_replicas bigint NOT NULL,
+  yaml text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_deployment PRIMARY KEY (uuid)
+);
+
+CREATE TABLE deployment_annotation (
+  deployment_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_deployment_annotation PRIMARY KEY (deployment_uuid, annotation_uuid)
+);
+
+CREATE TABLE deployment_condition (
+  deployment_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_update bigint NOT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_deployment_condition PRIMARY KEY (deployment_uuid, type)
+);
+
+CREATE TABLE deployment_label (
+  deployment_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_deployment_label PRIMARY KEY (deployment_uuid, label_uuid)
+);
+
+CREATE TABLE deployment_owner (
+  deployment_uuid bytea NOT NULL,
+  owner_uuid bytea NOT NULL,
+  kind varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  controller boolenum NOT NULL,
+  block_owner_deletion boolenum NOT NULL,
+
+  CONSTRAINT pk_deployment_owner PRIMARY KEY (deployment_uuid, owner_uuid)
+);
+
+CREATE TABLE downtime (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) DEFAULT NULL,
+  label_selector text DEFAULT NULL,
+  resource_uuid bytea DEFAULT NULL,
+  start_time bigint NOT NULL,
+  end_time bigint NOT NULL,
+  comment text NOT NULL,
+  locked boolenum NOT NULL,
+
+  CONSTRAINT pk_downtime PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_downtime_cluster_uuid_end_time ON downtime (cluster_uuid, end_time);
+COMMENT ON INDEX idx_downtime_cluster_uuid_end_time IS 'Filter for loading the downtimes of a cluster that have not ended yet';
+
+CREATE TABLE endpoint (
+  uuid bytea NOT NULL,
+  endpoint_slice_uuid bytea NOT NULL,
+  host_name varchar(253) NOT NULL,
+  node_name varchar(253) NOT NULL,
+  ready boolenum DEFAULT NULL,
+  serving boolenum DEFAULT NULL,
+  terminating boolenum DEFAULT NULL,
+  address varchar(253) NOT NULL,
+  protocol port_protocol NOT NULL,
+  port bigint NOT NULL,
+  port_name varchar(253) NOT NULL,
+  app_protocol varchar(253) NOT NULL,
+
+  CONSTRAINT pk_endpoint PRIMARY KEY (uuid)
+);
+
+CREATE TABLE endpoint_slice (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  address_type address_type NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_endpoint_slice PRIMARY KEY (uuid)
+);
+
+CREATE TABLE endpoint_slice_label (
+  endpoint_slice_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_endpoint_slice_label PRIMARY KEY (endpoint_slice_uuid, label_uuid)
+);
+
+CREATE TABLE endpoint_target_ref (
+  endpoint_slice_uuid bytea NOT NULL,
+  kind endpoint_target_ref_kind DEFAULT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  uid varchar(255) NOT NULL,
+  api_version varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+
+  CONSTRAINT pk_endpoint_target_ref PRIMARY KEY (endpoint_slice_uuid)
+);
+
+CREATE TABLE event (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  reference_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(270) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  reporting_controller varchar(253) DEFAULT NULL,
+  reporting_instance varchar(128) DEFAULT NULL,
+  action varchar(128) DEFAULT NULL,
+  reason varchar(128) NOT NULL,
+  note text NOT NULL,
+  type varchar(255) NOT NULL,
+  reference_kind varchar(255) NOT NULL,
+  reference_namespace varchar(255) DEFAULT NULL,
+  reference_name varchar(253) NOT NULL,
+  first_seen bigint NOT NULL,
+  last_seen bigint NOT NULL,
+  count bigint NOT NULL,
+  yaml text DEFAULT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_event PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_event_created ON event (created);
+COMMENT ON INDEX idx_event_created IS 'Filter for deleting old events';
+
+CREATE TABLE ingress (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  yaml text DEFAULT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_ingress PRIMARY KEY (uuid)
+);
+
+CREATE TABLE ingress_annotation (
+  ingress_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_ingress_annotation PRIMARY KEY (ingress_uuid, annotation_uuid)
+);
+
+CREATE TABLE ingress_backend_resource (
+  resource_uuid bytea NOT NULL,
+  ingress_uuid bytea NOT NULL,
+  ingress_rule_uuid bytea DEFAULT NULL,
+  api_group varchar(255) DEFAULT NULL,
+  kind varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+
+  CONSTRAINT pk_ingress_backend_resource PRIMARY KEY (resource_uuid, ingress_uuid)
+);
+
+CREATE TABLE ingress_backend_service (
+  service_uuid bytea NOT NULL,
+  ingress_uuid bytea NOT NULL,
+  ingress_rule_uuid bytea DEFAULT NULL,
+  service_name varchar(255) NOT NULL,
+  service_port_name varchar(255) DEFAULT NULL,
+  service_port_number bigint DEFAULT NULL,
+
+  CONSTRAINT pk_ingress_backend_service PRIMARY KEY (service_uuid, ingress_uuid)
+);
+
+CREATE TABLE ingress_label (
+  ingress_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_ingress_label PRIMARY KEY (ingress_uuid, label_uuid)
+);
+
+CREATE TABLE ingress_rule (
+  uuid bytea NOT NULL,
+  backend_uuid bytea NOT NULL,
+  ingress_uuid bytea NOT NULL,
+  host varchar(255) DEFAULT NULL,
+  path varchar(255) DEFAULT NULL,
+  path_type path_type NOT NULL,
+
+  CONSTRAINT pk_ingress_rule PRIMARY KEY (uuid)
+);
+
+CREATE TABLE ingress_tls (
+  ingress_uuid bytea NOT NULL,
+  tls_host varchar(255) NOT NULL,
+  tls_secret varchar(255) DEFAULT NULL,
+
+  CONSTRAINT pk_ingress_tls PRIMARY KEY (ingress_uuid)
+);
+
+CREATE TABLE job (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  parallelism bigint DEFAULT NULL,
+  completions bigint DEFAULT NULL,
+  active_deadline_seconds bigint DEFAULT NULL,
+  backoff_limit bigint DEFAULT NULL,
+  ttl_seconds_after_finished bigint DEFAULT NULL,
+  completion_mode completion_mode DEFAULT NULL,
+  suspend boolenum NOT NULL,
+  start_time bigint DEFAULT NULL,
+  completion_time bigint DEFAULT NULL,
+  active bigint NOT NULL,
+  succeeded bigint NOT NULL,
+  failed bigint NOT NULL,
+  yaml text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_job PRIMARY KEY (uuid)
+);
+
+CREATE TABLE job_annotation (
+  job_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_job_annotation PRIMARY KEY (job_uuid, annotation_uuid)
+);
+
+CREATE TABLE job_condition (
+  job_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_probe bigint DEFAULT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_job_condition PRIMARY KEY (job_uuid, type)
+);
+
+CREATE TABLE job_label (
+  job_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_job_label PRIMARY KEY (job_uuid, label_uuid)
+);
+
+CREATE TABLE job_owner (
+  job_uuid bytea NOT NULL,
+  owner_uuid bytea NOT NULL,
+  kind varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  controller boolenum NOT NULL,
+  block_owner_deletion boolenum NOT NULL,
+
+  CONSTRAINT pk_job_owner PRIMARY KEY (job_uuid, owner_uuid)
+);
+
+CREATE TABLE namespace (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  phase namespace_phase NOT NULL,
+  yaml text DEFAULT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_namespace PRIMARY KEY (uuid)
+);
+
+CREATE TABLE namespace_annotation (
+  namespace_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_namespace_annotation PRIMARY KEY (namespace_uuid, annotation_uuid)
+);
+
+CREATE TABLE namespace_condition (
+  namespace_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_namespace_condition PRIMARY KEY (namespace_uuid, type)
+);
+
+CREATE TABLE namespace_label (
+  namespace_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_namespace_label PRIMARY KEY (namespace_uuid, label_uuid)
+);
+
+CREATE TABLE node (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  pod_cidr varchar(255) NOT NULL,
+  num_ips bigint NOT NULL,
+  unschedulable boolenum NOT NULL,
+  ready boolenum NOT NULL,
+  cpu_capacity bigint NOT NULL,
+  cpu_allocatable bigint NOT NULL,
+  memory_capacity bigint NOT NULL,
+  memory_allocatable bigint NOT NULL,
+  pod_capacity bigint NOT NULL,
+  yaml text DEFAULT NULL,
+  roles varchar(255) NOT NULL,
+  machine_id varchar(255) NOT NULL,
+  system_uuid varchar(255) NOT NULL,
+  boot_id varchar(255) NOT NULL,
+  kernel_version varchar(255) NOT NULL,
+  os_image varchar(512) NOT NULL,
+  operating_system varchar(255) NOT NULL,
+  architecture varchar(255) NOT NULL,
+  container_runtime_version varchar(255) NOT NULL,
+  kubelet_version varchar(255) NOT NULL,
+  kube_proxy_version varchar(255) NOT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_node PRIMARY KEY (uuid)
+);
+
+CREATE TABLE node_annotation (
+  node_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_node_annotation PRIMARY KEY (node_uuid, annotation_uuid)
+);
+
+CREATE TABLE node_condition (
+  node_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_heartbeat bigint NOT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_node_condition PRIMARY KEY (node_uuid, type)
+);
+
+CREATE TABLE node_label (
+  node_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_node_label PRIMARY KEY (node_uuid, label_uuid)
+);
+
+CREATE TABLE node_volume (
+  node_uuid bytea NOT NULL,
+  name varchar(253) NOT NULL,
+  device_path varchar(255) NOT NULL,
+  mounted boolenum NOT NULL,
+
+  CONSTRAINT pk_node_volume PRIMARY KEY (node_uuid, name)
+);
+
+CREATE TABLE notification_outbox (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  kind varchar(63) NOT NULL,
+  name varchar(510) NOT NULL,
+  severity varchar(63) NOT NULL,
+  message text NOT NULL,
+  url text NOT NULL,
+  tags text NOT NULL,
+  extra_tags text NOT NULL,
+  labels text NOT NULL,
+  annotations text NOT NULL,
+  sinks text NOT NULL,
+  deleted boolenum NOT NULL,
+  version bigint NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_notification_outbox PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_notification_outbox_cluster_uuid_created ON notification_outbox (cluster_uuid, created);
+COMMENT ON INDEX idx_notification_outbox_cluster_uuid_created IS 'Draining the events of a cluster in order';
+
+CREATE TABLE notification_state (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  kind varchar(63) NOT NULL,
+  severity varchar(63) NOT NULL,
+  message text NOT NULL,
+  sent bigint NOT NULL,
+
+  CONSTRAINT pk_notification_state PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_notification_state_cluster_uuid ON notification_state (cluster_uuid);
+COMMENT ON INDEX idx_notification_state_cluster_uuid IS 'Filter for restoring the states of a cluster';
+
+CREATE TABLE persistent_volume (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  capacity bigint NOT NULL,
+  phase persistent_volume_phase NOT NULL,
+  reason varchar(255) DEFAULT NULL,
+  message text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  access_modes smallint DEFAULT NULL,
+  volume_mode volume_mode NOT NULL,
+  volume_source_type varchar(255) NOT NULL,
+  storage_class varchar(255) DEFAULT NULL,
+  volume_source text NOT NULL,
+  reclaim_policy reclaim_policy NOT NULL,
+  yaml text DEFAULT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_persistent_volume PRIMARY KEY (uuid)
+);
+
+CREATE TABLE persistent_volume_annotation (
+  persistent_volume_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_persistent_volume_annotation PRIMARY KEY (persistent_volume_uuid, annotation_uuid)
+);
+
+CREATE TABLE persistent_volume_claim_ref (
+  persistent_volume_uuid bytea NOT NULL,
+  kind varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+
+  CONSTRAINT pk_persistent_volume_claim_ref PRIMARY KEY (persistent_volume_uuid, uid)
+);
+
+CREATE TABLE persistent_volume_label (
+  persistent_volume_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_persistent_volume_label PRIMARY KEY (persistent_volume_uuid, label_uuid)
+);
+
+CREATE TABLE pod (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  node_name varchar(253) DEFAULT NULL,
+  nominated_node_name varchar(253) DEFAULT NULL,
+  ip varchar(255) DEFAULT NULL,
+  restart_policy restart_policy NOT NULL,
+  cpu_limits bigint DEFAULT NULL,
+  cpu_requests bigint DEFAULT NULL,
+  memory_limits bigint DEFAULT NULL,
+  memory_requests bigint DEFAULT NULL,
+  phase pod_phase NOT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text DEFAULT NULL,
+  reason varchar(255) DEFAULT NULL,
+  message text DEFAULT NULL,
+  qos pod_qos DEFAULT NULL,
+  yaml text DEFAULT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_pod PRIMARY KEY (uuid)
+);
+
+CREATE TABLE pod_annotation (
+  pod_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_pod_annotation PRIMARY KEY (pod_uuid, annotation_uuid)
+);
+
+CREATE TABLE pod_condition (
+  pod_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_probe bigint DEFAULT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_pod_condition PRIMARY KEY (pod_uuid, type)
+);
+
+CREATE TABLE pod_label (
+  pod_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_pod_label PRIMARY KEY (pod_uuid, label_uuid)
+);
+
+CREATE TABLE pod_metrics (
+  namespace varchar(255) NOT NULL,
+  pod_name varchar(253) NOT NULL,
+  container_name varchar(255) NOT NULL,
+  timestamp bigint NOT NULL,
+  duration bigint NOT NULL,
+  cpu_usage real NOT NULL,
+  memory_usage real NOT NULL,
+  storage_usage real NOT NULL,
+  ephemeral_storage_usage real NOT NULL,
+
+  CONSTRAINT pk_pod_metrics PRIMARY KEY (namespace, pod_name)
+);
+
+CREATE TABLE pod_owner (
+  pod_uuid bytea NOT NULL,
+  owner_uuid bytea NOT NULL,
+  kind varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  controller boolenum NOT NULL,
+  block_owner_deletion boolenum NOT NULL,
+
+  CONSTRAINT pk_pod_owner PRIMARY KEY (pod_uuid, owner_uuid)
+);
+
+CREATE TABLE pod_pvc (
+  pod_uuid bytea NOT NULL,
+  volume_name varchar(253) NOT NULL,
+  claim_name varchar(253) NOT NULL,
+  read_only boolenum NOT NULL,
+
+  CONSTRAINT pk_pod_pvc PRIMARY KEY (pod_uuid, volume_name, claim_name)
+);
+
+CREATE TABLE pod_volume (
+  pod_uuid bytea NOT NULL,
+  volume_name varchar(255) NOT NULL,
+  type varchar(255) NOT NULL,
+  source text NOT NULL,
+
+  CONSTRAINT pk_pod_volume PRIMARY KEY (pod_uuid, volume_name)
+);
+
+CREATE TABLE prometheus_cluster_metric (
+  cluster_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  value double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_cluster_metric PRIMARY KEY (cluster_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_cluster_metric_timestamp ON prometheus_cluster_metric (timestamp);
+COMMENT ON INDEX idx_prometheus_cluster_metric_timestamp IS 'Filter for deleting old cluster metrics';
+
+CREATE TABLE prometheus_cluster_metric_1h (
+  cluster_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_cluster_metric_1h PRIMARY KEY (cluster_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_cluster_metric_1h_timestamp ON prometheus_cluster_metric_1h (timestamp);
+COMMENT ON INDEX idx_prometheus_cluster_metric_1h_timestamp IS 'Filter for deleting old hourly cluster metrics';
+
+CREATE TABLE prometheus_cluster_metric_5m (
+  cluster_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_cluster_metric_5m PRIMARY KEY (cluster_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_cluster_metric_5m_timestamp ON prometheus_cluster_metric_5m (timestamp);
+COMMENT ON INDEX idx_prometheus_cluster_metric_5m_timestamp IS 'Filter for deleting old 5-minute cluster metrics';
+
+CREATE TABLE prometheus_container_metric (
+  container_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  value double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_container_metric PRIMARY KEY (container_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_container_metric_timestamp ON prometheus_container_metric (timestamp);
+COMMENT ON INDEX idx_prometheus_container_metric_timestamp IS 'Filter for deleting old container metrics';
+
+CREATE TABLE prometheus_container_metric_1h (
+  container_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_container_metric_1h PRIMARY KEY (container_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_container_metric_1h_timestamp ON prometheus_container_metric_1h (timestamp);
+COMMENT ON INDEX idx_prometheus_container_metric_1h_timestamp IS 'Filter for deleting old hourly container metrics';
+
+CREATE TABLE prometheus_container_metric_5m (
+  container_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_container_metric_5m PRIMARY KEY (container_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_container_metric_5m_timestamp ON prometheus_container_metric_5m (timestamp);
+COMMENT ON INDEX idx_prometheus_container_metric_5m_timestamp IS 'Filter for deleting old 5-minute container metrics';
+
+CREATE TABLE prometheus_node_metric (
+  node_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  value double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_node_metric PRIMARY KEY (node_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_node_metric_timestamp ON prometheus_node_metric (timestamp);
+COMMENT ON INDEX idx_prometheus_node_metric_timestamp IS 'Filter for deleting old node metrics';
+
+CREATE TABLE prometheus_node_metric_1h (
+  node_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_node_metric_1h PRIMARY KEY (node_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_node_metric_1h_timestamp ON prometheus_node_metric_1h (timestamp);
+COMMENT ON INDEX idx_prometheus_node_metric_1h_timestamp IS 'Filter for deleting old hourly node metrics';
+
+CREATE TABLE prometheus_node_metric_5m (
+  node_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_node_metric_5m PRIMARY KEY (node_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_node_metric_5m_timestamp ON prometheus_node_metric_5m (timestamp);
+COMMENT ON INDEX idx_prometheus_node_metric_5m_timestamp IS 'Filter for deleting old 5-minute node metrics';
+
+CREATE TABLE prometheus_pod_metric (
+  pod_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  value double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_pod_metric PRIMARY KEY (pod_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_pod_metric_timestamp ON prometheus_pod_metric (timestamp);
+COMMENT ON INDEX idx_prometheus_pod_metric_timestamp IS 'Filter for deleting old pod metrics';
+
+CREATE TABLE prometheus_pod_metric_1h (
+  pod_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_pod_metric_1h PRIMARY KEY (pod_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_pod_metric_1h_timestamp ON prometheus_pod_metric_1h (timestamp);
+COMMENT ON INDEX idx_prometheus_pod_metric_1h_timestamp IS 'Filter for deleting old hourly pod metrics';
+
+CREATE TABLE prometheus_pod_metric_5m (
+  pod_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_pod_metric_5m PRIMARY KEY (pod_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_pod_metric_5m_timestamp ON prometheus_pod_metric_5m (timestamp);
+COMMENT ON INDEX idx_prometheus_pod_metric_5m_timestamp IS 'Filter for deleting old 5-minute pod metrics';
+
+CREATE TABLE pvc (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  desired_access_modes smallint NOT NULL,
+  actual_access_modes smallint DEFAULT NULL,
+  minimum_capacity bigint DEFAULT NULL,
+  actual_capacity bigint DEFAULT NULL,
+  phase pvc_phase NOT NULL,
+  volume_name varchar(253) DEFAULT NULL,
+  volume_mode volume_mode DEFAULT NULL,
+  storage_class varchar(255) DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  yaml text DEFAULT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_pvc PRIMARY KEY (uuid)
+);
+
+CREATE TABLE pvc_annotation (
+  pvc_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_pvc_annotation PRIMARY KEY (pvc_uuid, annotation_uuid)
+);
+
+CREATE TABLE pvc_condition (
+  pvc_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_probe bigint DEFAULT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_pvc_condition PRIMARY KEY (pvc_uuid, type)
+);
+
+CREATE TABLE pvc_label (
+  pvc_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_pvc_label PRIMARY KEY (pvc_uuid, label_uuid)
+);
+
+CREATE TABLE pvc_metric (
+  pvc_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  value double precision NOT NULL,
+
+  CONSTRAINT pk_pvc_metric PRIMARY KEY (pvc_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_pvc_metric_timestamp ON pvc_metric (timestamp);
+COMMENT ON INDEX idx_pvc_metric_timestamp IS 'Filter for deleting old PVC metrics';
+
+CREATE TABLE pvc_metric_1h (
+  pvc_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_pvc_metric_1h PRIMARY KEY (pvc_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_pvc_metric_1h_timestamp ON pvc_metric_1h (timestamp);
+COMMENT ON INDEX idx_pvc_metric_1h_timestamp IS 'Filter for deleting old hourly PVC metrics';
+
+CREATE TABLE pvc_metric_5m (
+  pvc_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_pvc_metric_5m PRIMARY KEY (pvc_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_pvc_metric_5m_timestamp ON pvc_metric_5m (timestamp);
+COMMENT ON INDEX idx_pvc_metric_5m_timestamp IS 'Filter for deleting old 5-minute PVC metrics';
+
+CREATE TABLE replica_set (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  desired_replicas bigint NOT NULL,
+  min_ready_seconds bigint NOT NULL,
+  actual_replicas bigint NOT NULL,
+  fully_labeled_replicas bigint NOT NULL,
+  ready_replicas bigint NOT NULL,
+  available_replicas bigint NOT NULL,
+  yaml text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_replica_set PRIMARY KEY (uuid)
+);
+
+CREATE TABLE replica_set_annotation (
+  replica_set_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_replica_set_annotation PRIMARY KEY (replica_set_uuid, annotation_uuid)
+);
+
+CREATE TABLE replica_set_condition (
+  replica_set_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_replica_set_condition PRIMARY KEY (replica_set_uuid, type)
+);
+
+CREATE TABLE replica_set_label (
+  replica_set_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_replica_set_label PRIMARY KEY (replica_set_uuid, label_uuid)
+);
+
+CREATE TABLE replica_set_owner (
+  replica_set_uuid bytea NOT NULL,
+  owner_uuid bytea NOT NULL,
+  kind varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  controller boolenum NOT NULL,
+  block_owner_deletion boolenum NOT NULL,
+
+  CONSTRAINT pk_replica_set_owner PRIMARY KEY (replica_set_uuid, owner_uuid)
+);
+
+CREATE TABLE secret (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  type varchar(255) NOT NULL,
+  immutable boolenum NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_secret PRIMARY KEY (uuid)
+);
+
+CREATE TABLE secret_annotation (
+  secret_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_secret_annotation PRIMARY KEY (secret_uuid, annotation_uuid)
+);
+
+CREATE TABLE secret_label (
+  secret_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_secret_label PRIMARY KEY (secret_uuid, label_uuid)
+);
+
+CREATE TABLE selector (
+  uuid bytea NOT NULL,
+  name varchar(317) NOT NULL,
+  value varchar(255) NOT NULL,
+
+  CONSTRAINT pk_selector PRIMARY KEY (uuid)
+);
+
+CREATE TABLE service (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  cluster_ip varchar(255) NOT NULL,
+  cluster_ips varchar(255) NOT NULL,
+  type service_type NOT NULL,
+  external_ips varchar(255) DEFAULT NULL,
+  session_affinity session_affinity NOT NULL,
+  external_name varchar(255) DEFAULT NULL,
+  external_traffic_policy traffic_policy DEFAULT NULL,
+  health_check_node_port bigint DEFAULT NULL,
+  publish_not_ready_addresses boolenum NOT NULL,
+  ip_families ip_families DEFAULT NULL,
+  ip_family_policy ip_family_policy DEFAULT NULL,
+  allocate_load_balancer_node_ports boolenum NOT NULL,
+  load_balancer_class varchar(255) DEFAULT NULL,
+  internal_traffic_policy traffic_policy NOT NULL,
+  yaml text DEFAULT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_service PRIMARY KEY (uuid)
+);
+
+CREATE TABLE service_annotation (
+  service_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_service_annotation PRIMARY KEY (service_uuid, annotation_uuid)
+);
+
+CREATE TABLE service_condition (
+  service_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  observed_generation bigint DEFAULT NULL,
+  last_transition bigint DEFAULT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_service_condition PRIMARY KEY (service_uuid, type)
+);
+
+CREATE TABLE service_label (
+  service_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_service_label PRIMARY KEY (service_uuid, label_uuid)
+);
+
+CREATE TABLE service_pod (
+  service_uuid bytea NOT NULL,
+  pod_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_service_pod PRIMARY KEY (service_uuid, pod_uuid)
+);
+
+CREATE TABLE service_port (
+  service_uuid bytea NOT NULL,
+  name varchar(255) NOT NULL,
+  protocol port_protocol NOT NULL,
+  app_protocol varchar(255) NOT NULL,
+  port bigint NOT NULL,
+  target_port varchar(15) NOT NULL,
+  node_port bigint NOT NULL,
+
+  CONSTRAINT pk_service_port PRIMARY KEY (service_uuid, name)
+);
+
+CREATE TABLE service_selector (
+  service_uuid bytea NOT NULL,
+  selector_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_service_selector PRIMARY KEY (service_uuid, selector_uuid)
+);
+
+CREATE TABLE stateful_set (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  desired_replicas bigint NOT NULL,
+  service_name varchar(253) NOT NULL,
+  pod_management_policy pod_management_policy NOT NULL,
+  update_strategy update_strategy NOT NULL,
+  min_ready_seconds bigint NOT NULL,
+  persistent_volume_claim_retention_policy_when_deleted pvc_retention_policy NOT NULL,
+  persistent_volume_claim_retention_policy_when_scaled pvc_retention_policy NOT NULL,
+  ordinals bigint NOT NULL,
+  actual_replicas bigint NOT NULL,
+  ready_replicas bigint NOT NULL,
+  current_replicas bigint NOT NULL,
+  updated_replicas bigint NOT NULL,
+  available_replicas bigint NOT NULL,
+  yaml text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_stateful_set PRIMARY KEY (uuid)
+);
+
+CREATE TABLE stateful_set_annotation (
+  stateful_set_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_stateful_set_annotation PRIMARY KEY (stateful_set_uuid, annotation_uuid)
+);
+
+CREATE TABLE stateful_set_condition (
+  stateful_set_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status condition_status NOT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_stateful_set_condition PRIMARY KEY (stateful_set_uuid, type)
+);
+
+CREATE TABLE stateful_set_label (
+  stateful_set_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_stateful_set_label PRIMARY KEY (stateful_set_uuid, label_uuid)
+);
+
+CREATE TABLE stateful_set_owner (
+  stateful_set_uuid bytea NOT NULL,
+  owner_uuid bytea NOT NULL,
+  kind varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  controller boolenum NOT NULL,
+  block_owner_deletion boolenum NOT NULL,
+
+  CONSTRAINT pk_stateful_set_owner PRIMARY KEY (stateful_set_uuid, owner_uuid)
+);
+
+CREATE TABLE favorite (
+  resource_uuid bytea NOT NULL,
+  kind varchar(255) NOT NULL,
+  username varchar(254) NOT NULL,
+  priority bigint,
+
+  CONSTRAINT pk_favorite PRIMARY KEY (resource_uuid, username)
+);
+
+CREATE INDEX idx_favorite_username ON favorite (username, kind);
+COMMENT ON INDEX idx_favorite_username IS 'Favorites filtered by username and kind';
+
+CREATE TABLE kubernetes_instance (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  version varchar(255) NOT NULL,
+  kubernetes_version varchar(255) NOT NULL,
+  kubernetes_heartbeat bigint DEFAULT NULL,
+  kubernetes_api_reachable boolenum NOT NULL,
+  role instance_role NOT NULL DEFAULT 'leader',
+  message text DEFAULT NULL,
+  heartbeat bigint NOT NULL,
+
+  CONSTRAINT pk_kubernetes_instance PRIMARY KEY (uuid)
+);
+
+CREATE TABLE config (
+  cluster_uuid bytea NOT NULL,
+  "key" varchar(255) NOT NULL,
+  value varchar(255) NOT NULL,
+  locked boolenum NOT NULL,
+
+  CONSTRAINT pk_config PRIMARY KEY ("key", cluster_uuid),
+  CONSTRAINT ck_config_key CHECK ("key" IN (
+    'notifications.url',
+    'notifications.username',
+    'notifications.password',
+    'notifications.kubernetes_web_url',
+    'prometheus.url',
+    'prometheus.insecure',
+    'prometheus.username',
+    'prometheus.password'
+  ))
+);
+
+CREATE VIEW prometheus_cluster_metric_series AS
+  SELECT cluster_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_cluster_metric
+  UNION ALL
+  SELECT cluster_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_cluster_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_cluster_metric)
+  UNION ALL
+  SELECT cluster_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_cluster_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_cluster_metric_5m);
+
+CREATE VIEW prometheus_container_metric_series AS
+  SELECT container_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_container_metric
+  UNION ALL
+  SELECT container_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_container_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_container_metric)
+  UNION ALL
+  SELECT container_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_container_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_container_metric_5m);
+
+CREATE VIEW prometheus_node_metric_series AS
+  SELECT node_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_node_metric
+  UNION ALL
+  SELECT node_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_node_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_node_metric)
+  UNION ALL
+  SELECT node_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_node_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_node_metric_5m);
+
+CREATE VIEW prometheus_pod_metric_series AS
+  SELECT pod_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_pod_metric
+  UNION ALL
+  SELECT pod_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_pod_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_pod_metric)
+  UNION ALL
+  SELECT pod_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_pod_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_pod_metric_5m);
+
+CREATE VIEW pvc_metric_series AS
+  SELECT pvc_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM pvc_metric
+  UNION ALL
+  SELECT pvc_uuid, timestamp, category, name, min, avg, max
+  FROM pvc_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM pvc_metric)
+  UNION ALL
+  SELECT pvc_uuid, timestamp, category, name, min, avg, max
+  FROM pvc_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM pvc_metric_5m);
+
+CREATE TABLE kubernetes_schema (
+  id bigint GENERATED ALWAYS AS IDENTITY,
+  version varchar(255) NOT NULL,
+  timestamp bigint NOT NULL,
+  success boolenum DEFAULT NULL,
+  reason text DEFAULT NULL,
+
+  CONSTRAINT pk_kubernetes_schema PRIMARY KEY (id),
+  CONSTRAINT idx_kubernetes_schema_version UNIQUE (version)
+);
+
+INSERT INTO kubernetes_schema (version, timestamp, success, reason)
+VALUES ('0.5.0', (extract(epoch from now()) * 1000)::bigint, 'y', 'Initial import');
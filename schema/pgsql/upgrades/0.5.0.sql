@@ -0,0 +1,345 @@
+CREATE TYPE instance_role AS ENUM ('leader', 'standby');
+
+ALTER TABLE kubernetes_instance
+  ADD COLUMN role instance_role NOT NULL DEFAULT 'leader';
+
+ALTER TABLE persistent_volume
+  ADD COLUMN icinga_state icinga_state NOT NULL DEFAULT 'unknown',
+  ADD COLUMN icinga_state_reason text NOT NULL DEFAULT '';
+ALTER TABLE persistent_volume
+  ALTER COLUMN icinga_state DROP DEFAULT,
+  ALTER COLUMN icinga_state_reason DROP DEFAULT;
+
+ALTER TABLE pvc
+  ADD COLUMN icinga_state icinga_state NOT NULL DEFAULT 'unknown',
+  ADD COLUMN icinga_state_reason text NOT NULL DEFAULT '';
+ALTER TABLE pvc
+  ALTER COLUMN icinga_state DROP DEFAULT,
+  ALTER COLUMN icinga_state_reason DROP DEFAULT;
+
+CREATE INDEX idx_container_log_last_update ON container_log (last_update);
+COMMENT ON INDEX idx_container_log_last_update IS 'Filter for deleting old container logs';
+
+CREATE TABLE custom_resource (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  api_group varchar(253) NOT NULL,
+  api_version varchar(63) NOT NULL,
+  resource varchar(63) NOT NULL,
+  kind varchar(63) NOT NULL,
+  namespace varchar(255) NOT NULL,
+  name varchar(253) NOT NULL,
+  uid varchar(255) NOT NULL,
+  resource_version varchar(255) NOT NULL,
+  yaml text DEFAULT NULL,
+  icinga_state icinga_state NOT NULL,
+  icinga_state_reason text NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_custom_resource PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_custom_resource_api_group_resource ON custom_resource (api_group, resource);
+COMMENT ON INDEX idx_custom_resource_api_group_resource IS 'Filter for synchronizing resources of one API';
+
+CREATE TABLE custom_resource_annotation (
+  custom_resource_uuid bytea NOT NULL,
+  annotation_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_custom_resource_annotation PRIMARY KEY (custom_resource_uuid, annotation_uuid)
+);
+
+CREATE TABLE custom_resource_condition (
+  custom_resource_uuid bytea NOT NULL,
+  type varchar(255) NOT NULL,
+  status varchar(255) NOT NULL,
+  last_transition bigint NOT NULL,
+  reason varchar(255) NOT NULL,
+  message text,
+
+  CONSTRAINT pk_custom_resource_condition PRIMARY KEY (custom_resource_uuid, type)
+);
+
+CREATE TABLE custom_resource_label (
+  custom_resource_uuid bytea NOT NULL,
+  label_uuid bytea NOT NULL,
+
+  CONSTRAINT pk_custom_resource_label PRIMARY KEY (custom_resource_uuid, label_uuid)
+);
+
+CREATE TABLE downtime (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  namespace varchar(255) DEFAULT NULL,
+  label_selector text DEFAULT NULL,
+  resource_uuid bytea DEFAULT NULL,
+  start_time bigint NOT NULL,
+  end_time bigint NOT NULL,
+  comment text NOT NULL,
+  locked boolenum NOT NULL,
+
+  CONSTRAINT pk_downtime PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_downtime_cluster_uuid_end_time ON downtime (cluster_uuid, end_time);
+COMMENT ON INDEX idx_downtime_cluster_uuid_end_time IS 'Filter for loading the downtimes of a cluster that have not ended yet';
+
+CREATE TABLE notification_outbox (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  kind varchar(63) NOT NULL,
+  name varchar(510) NOT NULL,
+  severity varchar(63) NOT NULL,
+  message text NOT NULL,
+  url text NOT NULL,
+  tags text NOT NULL,
+  extra_tags text NOT NULL,
+  labels text NOT NULL,
+  annotations text NOT NULL,
+  sinks text NOT NULL,
+  deleted boolenum NOT NULL,
+  version bigint NOT NULL,
+  created bigint NOT NULL,
+
+  CONSTRAINT pk_notification_outbox PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_notification_outbox_cluster_uuid_created ON notification_outbox (cluster_uuid, created);
+COMMENT ON INDEX idx_notification_outbox_cluster_uuid_created IS 'Draining the events of a cluster in order';
+
+CREATE TABLE notification_state (
+  uuid bytea NOT NULL,
+  cluster_uuid bytea NOT NULL,
+  kind varchar(63) NOT NULL,
+  severity varchar(63) NOT NULL,
+  message text NOT NULL,
+  sent bigint NOT NULL,
+
+  CONSTRAINT pk_notification_state PRIMARY KEY (uuid)
+);
+
+CREATE INDEX idx_notification_state_cluster_uuid ON notification_state (cluster_uuid);
+COMMENT ON INDEX idx_notification_state_cluster_uuid IS 'Filter for restoring the states of a cluster';
+
+CREATE TABLE pvc_metric (
+  pvc_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  value double precision NOT NULL,
+
+  CONSTRAINT pk_pvc_metric PRIMARY KEY (pvc_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_pvc_metric_timestamp ON pvc_metric (timestamp);
+COMMENT ON INDEX idx_pvc_metric_timestamp IS 'Filter for deleting old PVC metrics';
+
+CREATE TABLE prometheus_cluster_metric_1h (
+  cluster_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_cluster_metric_1h PRIMARY KEY (cluster_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_cluster_metric_1h_timestamp ON prometheus_cluster_metric_1h (timestamp);
+COMMENT ON INDEX idx_prometheus_cluster_metric_1h_timestamp IS 'Filter for deleting old hourly cluster metrics';
+
+CREATE TABLE prometheus_cluster_metric_5m (
+  cluster_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_cluster_metric_5m PRIMARY KEY (cluster_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_cluster_metric_5m_timestamp ON prometheus_cluster_metric_5m (timestamp);
+COMMENT ON INDEX idx_prometheus_cluster_metric_5m_timestamp IS 'Filter for deleting old 5-minute cluster metrics';
+
+CREATE TABLE prometheus_container_metric_1h (
+  container_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_container_metric_1h PRIMARY KEY (container_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_container_metric_1h_timestamp ON prometheus_container_metric_1h (timestamp);
+COMMENT ON INDEX idx_prometheus_container_metric_1h_timestamp IS 'Filter for deleting old hourly container metrics';
+
+CREATE TABLE prometheus_container_metric_5m (
+  container_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_container_metric_5m PRIMARY KEY (container_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_container_metric_5m_timestamp ON prometheus_container_metric_5m (timestamp);
+COMMENT ON INDEX idx_prometheus_container_metric_5m_timestamp IS 'Filter for deleting old 5-minute container metrics';
+
+CREATE TABLE prometheus_node_metric_1h (
+  node_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_node_metric_1h PRIMARY KEY (node_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_node_metric_1h_timestamp ON prometheus_node_metric_1h (timestamp);
+COMMENT ON INDEX idx_prometheus_node_metric_1h_timestamp IS 'Filter for deleting old hourly node metrics';
+
+CREATE TABLE prometheus_node_metric_5m (
+  node_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_node_metric_5m PRIMARY KEY (node_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_node_metric_5m_timestamp ON prometheus_node_metric_5m (timestamp);
+COMMENT ON INDEX idx_prometheus_node_metric_5m_timestamp IS 'Filter for deleting old 5-minute node metrics';
+
+CREATE TABLE prometheus_pod_metric_1h (
+  pod_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_pod_metric_1h PRIMARY KEY (pod_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_pod_metric_1h_timestamp ON prometheus_pod_metric_1h (timestamp);
+COMMENT ON INDEX idx_prometheus_pod_metric_1h_timestamp IS 'Filter for deleting old hourly pod metrics';
+
+CREATE TABLE prometheus_pod_metric_5m (
+  pod_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_prometheus_pod_metric_5m PRIMARY KEY (pod_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_prometheus_pod_metric_5m_timestamp ON prometheus_pod_metric_5m (timestamp);
+COMMENT ON INDEX idx_prometheus_pod_metric_5m_timestamp IS 'Filter for deleting old 5-minute pod metrics';
+
+CREATE TABLE pvc_metric_1h (
+  pvc_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_pvc_metric_1h PRIMARY KEY (pvc_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_pvc_metric_1h_timestamp ON pvc_metric_1h (timestamp);
+COMMENT ON INDEX idx_pvc_metric_1h_timestamp IS 'Filter for deleting old hourly PVC metrics';
+
+CREATE TABLE pvc_metric_5m (
+  pvc_uuid bytea NOT NULL,
+  timestamp bigint NOT NULL,
+  category varchar(255) NOT NULL,
+  name varchar(255) NOT NULL,
+  min double precision NOT NULL,
+  avg double precision NOT NULL,
+  max double precision NOT NULL,
+
+  CONSTRAINT pk_pvc_metric_5m PRIMARY KEY (pvc_uuid, timestamp, category, name)
+);
+
+CREATE INDEX idx_pvc_metric_5m_timestamp ON pvc_metric_5m (timestamp);
+COMMENT ON INDEX idx_pvc_metric_5m_timestamp IS 'Filter for deleting old 5-minute PVC metrics';
+
+CREATE VIEW prometheus_cluster_metric_series AS
+  SELECT cluster_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_cluster_metric
+  UNION ALL
+  SELECT cluster_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_cluster_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_cluster_metric)
+  UNION ALL
+  SELECT cluster_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_cluster_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_cluster_metric_5m);
+
+CREATE VIEW prometheus_container_metric_series AS
+  SELECT container_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_container_metric
+  UNION ALL
+  SELECT container_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_container_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_container_metric)
+  UNION ALL
+  SELECT container_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_container_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_container_metric_5m);
+
+CREATE VIEW prometheus_node_metric_series AS
+  SELECT node_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_node_metric
+  UNION ALL
+  SELECT node_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_node_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_node_metric)
+  UNION ALL
+  SELECT node_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_node_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_node_metric_5m);
+
+CREATE VIEW prometheus_pod_metric_series AS
+  SELECT pod_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM prometheus_pod_metric
+  UNION ALL
+  SELECT pod_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_pod_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_pod_metric)
+  UNION ALL
+  SELECT pod_uuid, timestamp, category, name, min, avg, max
+  FROM prometheus_pod_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM prometheus_pod_metric_5m);
+
+CREATE VIEW pvc_metric_series AS
+  SELECT pvc_uuid, timestamp, category, name, value AS min, value AS avg, value AS max
+  FROM pvc_metric
+  UNION ALL
+  SELECT pvc_uuid, timestamp, category, name, min, avg, max
+  FROM pvc_metric_5m
+  WHERE timestamp + 300000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM pvc_metric)
+  UNION ALL
+  SELECT pvc_uuid, timestamp, category, name, min, avg, max
+  FROM pvc_metric_1h
+  WHERE timestamp + 3600000 <= (SELECT COALESCE(MIN(timestamp), 9223372036854775807) FROM pvc_metric_5m);